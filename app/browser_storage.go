@@ -0,0 +1,347 @@
+//go:build js && wasm
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"syscall/js"
+)
+
+const (
+	storageDBVersion = 1
+	storageFileStore = "files"
+)
+
+func newBrowserStorage(name string, schedule func(fn func())) *browserStorage {
+	storage := &browserStorage{
+		name:           name,
+		schedule:       schedule,
+		jsLocalStorage: js.Null(),
+		jsDB:           js.Null(),
+	}
+	// NOTE: Accessing localStorage throws when storage is disabled by the
+	// user or the browser.
+	if err := jsTry(func() {
+		storage.jsLocalStorage = js.Global().Get("localStorage")
+	}); err != nil || storage.jsLocalStorage.IsUndefined() {
+		logger.Warn("JavaScript localStorage not available")
+		storage.jsLocalStorage = js.Null()
+	}
+	return storage
+}
+
+// browserStorage is a Storage that keeps values in localStorage and files
+// in an IndexedDB database.
+type browserStorage struct {
+	name           string
+	schedule       func(fn func())
+	jsLocalStorage js.Value
+	jsDB           js.Value
+	dbErr          error
+	dbOpening      bool
+	dbPending      []func(jsDB js.Value, err error)
+}
+
+func (s *browserStorage) Value(key string) (string, bool) {
+	if s.jsLocalStorage.IsNull() {
+		return "", false
+	}
+	jsValue := s.jsLocalStorage.Call("getItem", s.valueKey(key))
+	if jsValue.IsNull() {
+		return "", false
+	}
+	return jsValue.String(), true
+}
+
+func (s *browserStorage) SetValue(key, value string) error {
+	if s.jsLocalStorage.IsNull() {
+		return ErrStorageUnavailable
+	}
+	// NOTE: setItem throws when the quota is exceeded.
+	return jsTry(func() {
+		s.jsLocalStorage.Call("setItem", s.valueKey(key), value)
+	})
+}
+
+func (s *browserStorage) DeleteValue(key string) error {
+	if s.jsLocalStorage.IsNull() {
+		return ErrStorageUnavailable
+	}
+	s.jsLocalStorage.Call("removeItem", s.valueKey(key))
+	return nil
+}
+
+func (s *browserStorage) ReadFile(filePath string, callback func(data []byte, err error)) {
+	s.withStore("readonly", func(jsStore js.Value, err error) {
+		if err != nil {
+			s.schedule(func() { callback(nil, err) })
+			return
+		}
+		jsRequest := jsStore.Call("get", storagePath(filePath))
+		onIDBRequest(jsRequest, func(jsResult js.Value, err error) {
+			if err != nil {
+				s.schedule(func() { callback(nil, err) })
+				return
+			}
+			if jsResult.IsUndefined() {
+				s.schedule(func() { callback(nil, ErrFileNotFound) })
+				return
+			}
+			data := make([]byte, jsResult.Length())
+			js.CopyBytesToGo(data, jsResult)
+			s.schedule(func() { callback(data, nil) })
+		})
+	})
+}
+
+func (s *browserStorage) WriteFile(filePath string, data []byte, callback func(err error)) {
+	jsData := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(jsData, data)
+	s.withStore("readwrite", func(jsStore js.Value, err error) {
+		if err != nil {
+			s.complete(callback, err)
+			return
+		}
+		if err := jsTry(func() {
+			jsStore.Call("put", jsData, storagePath(filePath))
+		}); err != nil {
+			s.complete(callback, err)
+			return
+		}
+		onIDBTransaction(jsStore.Get("transaction"), func(err error) {
+			s.complete(callback, err)
+		})
+	})
+}
+
+func (s *browserStorage) DeleteFile(filePath string, callback func(err error)) {
+	s.withStore("readwrite", func(jsStore js.Value, err error) {
+		if err != nil {
+			s.complete(callback, err)
+			return
+		}
+		if err := jsTry(func() {
+			jsStore.Call("delete", storagePath(filePath))
+		}); err != nil {
+			s.complete(callback, err)
+			return
+		}
+		onIDBTransaction(jsStore.Get("transaction"), func(err error) {
+			s.complete(callback, err)
+		})
+	})
+}
+
+func (s *browserStorage) ListFiles(dir string, callback func(paths []string, err error)) {
+	s.withStore("readonly", func(jsStore js.Value, err error) {
+		if err != nil {
+			s.schedule(func() { callback(nil, err) })
+			return
+		}
+		var jsRequest js.Value
+		if prefix := storageDirPrefix(dir); prefix != "" {
+			jsRange := js.Global().Get("IDBKeyRange").Call("bound", prefix, prefix+"\uffff")
+			jsRequest = jsStore.Call("getAllKeys", jsRange)
+		} else {
+			jsRequest = jsStore.Call("getAllKeys")
+		}
+		onIDBRequest(jsRequest, func(jsResult js.Value, err error) {
+			if err != nil {
+				s.schedule(func() { callback(nil, err) })
+				return
+			}
+			paths := make([]string, jsResult.Length())
+			for i := range paths {
+				paths[i] = jsResult.Index(i).String()
+			}
+			slices.Sort(paths)
+			s.schedule(func() { callback(paths, nil) })
+		})
+	})
+}
+
+func (s *browserStorage) Estimate(callback func(estimate StorageEstimate, err error)) {
+	jsStorageManager := js.Global().Get("navigator").Get("storage")
+	if jsStorageManager.IsUndefined() || jsStorageManager.Get("estimate").IsUndefined() {
+		s.schedule(func() { callback(StorageEstimate{}, ErrStorageUnavailable) })
+		return
+	}
+	awaitPromise(jsStorageManager.Call("estimate"), func(jsEstimate js.Value, err error) {
+		if err != nil {
+			err = fmt.Errorf("storage estimate failed: %w", err)
+			s.schedule(func() { callback(StorageEstimate{}, err) })
+			return
+		}
+		estimate := StorageEstimate{
+			Usage: jsInt64(jsEstimate.Get("usage")),
+			Quota: jsInt64(jsEstimate.Get("quota")),
+		}
+		s.schedule(func() { callback(estimate, nil) })
+	})
+}
+
+func (s *browserStorage) valueKey(key string) string {
+	return s.name + ":" + key
+}
+
+func (s *browserStorage) complete(callback func(err error), err error) {
+	if callback != nil {
+		s.schedule(func() { callback(err) })
+	}
+}
+
+// withStore calls fn with the file object store of a new transaction,
+// once the database has been opened.
+func (s *browserStorage) withStore(mode string, fn func(jsStore js.Value, err error)) {
+	s.withDB(func(jsDB js.Value, err error) {
+		if err != nil {
+			fn(js.Null(), err)
+			return
+		}
+		var jsStore js.Value
+		if err := jsTry(func() {
+			jsStore = jsDB.Call("transaction", storageFileStore, mode).Call("objectStore", storageFileStore)
+		}); err != nil {
+			fn(js.Null(), err)
+			return
+		}
+		fn(jsStore, nil)
+	})
+}
+
+// withDB calls fn with the database, opening it first if necessary.
+func (s *browserStorage) withDB(fn func(jsDB js.Value, err error)) {
+	if !s.jsDB.IsNull() || s.dbErr != nil {
+		fn(s.jsDB, s.dbErr)
+		return
+	}
+	s.dbPending = append(s.dbPending, fn)
+	if s.dbOpening {
+		return
+	}
+	s.dbOpening = true
+
+	jsIndexedDB := js.Global().Get("indexedDB")
+	if jsIndexedDB.IsUndefined() || jsIndexedDB.IsNull() {
+		s.onDBOpened(js.Null(), ErrStorageUnavailable)
+		return
+	}
+	var jsRequest js.Value
+	if err := jsTry(func() {
+		jsRequest = jsIndexedDB.Call("open", s.name, storageDBVersion)
+	}); err != nil {
+		s.onDBOpened(js.Null(), errors.Join(ErrStorageUnavailable, err))
+		return
+	}
+
+	upgradeCallback := js.FuncOf(func(this js.Value, args []js.Value) any {
+		jsDB := jsRequest.Get("result")
+		if !jsDB.Get("objectStoreNames").Call("contains", storageFileStore).Bool() {
+			jsDB.Call("createObjectStore", storageFileStore)
+		}
+		return nil
+	})
+	jsRequest.Set("onupgradeneeded", upgradeCallback)
+	onIDBRequest(jsRequest, func(jsResult js.Value, err error) {
+		upgradeCallback.Release()
+		if err != nil {
+			s.onDBOpened(js.Null(), errors.Join(ErrStorageUnavailable, err))
+			return
+		}
+		s.onDBOpened(jsResult, nil)
+	})
+}
+
+func (s *browserStorage) onDBOpened(jsDB js.Value, err error) {
+	if err != nil {
+		logger.Warn("Failed to open storage database",
+			slog.String("error", err.Error()),
+		)
+	}
+	s.jsDB = jsDB
+	s.dbErr = err
+	s.dbOpening = false
+	pending := s.dbPending
+	s.dbPending = nil
+	for _, fn := range pending {
+		fn(jsDB, err)
+	}
+}
+
+// onIDBRequest calls fn once the specified IndexedDB request succeeds
+// or fails.
+func onIDBRequest(jsRequest js.Value, fn func(jsResult js.Value, err error)) {
+	var onSuccess, onError js.Func
+	onSuccess = js.FuncOf(func(this js.Value, args []js.Value) any {
+		onSuccess.Release()
+		onError.Release()
+		fn(jsRequest.Get("result"), nil)
+		return nil
+	})
+	onError = js.FuncOf(func(this js.Value, args []js.Value) any {
+		onSuccess.Release()
+		onError.Release()
+		fn(js.Null(), fmt.Errorf("indexeddb request failed: %s", jsErrorMessage([]js.Value{jsRequest.Get("error")})))
+		return nil
+	})
+	jsRequest.Set("onsuccess", onSuccess)
+	jsRequest.Set("onerror", onError)
+}
+
+// onIDBTransaction calls fn once the specified IndexedDB transaction has
+// been committed or has failed.
+func onIDBTransaction(jsTransaction js.Value, fn func(err error)) {
+	// NOTE: A failed request fires error and then abort on the transaction,
+	// so only the first event is handled.
+	var done bool
+	var onComplete, onError js.Func
+	finish := func(err error) {
+		if done {
+			return
+		}
+		done = true
+		jsTransaction.Set("oncomplete", js.Null())
+		jsTransaction.Set("onerror", js.Null())
+		jsTransaction.Set("onabort", js.Null())
+		onComplete.Release()
+		onError.Release()
+		fn(err)
+	}
+	onComplete = js.FuncOf(func(this js.Value, args []js.Value) any {
+		finish(nil)
+		return nil
+	})
+	onError = js.FuncOf(func(this js.Value, args []js.Value) any {
+		finish(fmt.Errorf("indexeddb transaction failed: %s", jsErrorMessage([]js.Value{jsTransaction.Get("error")})))
+		return nil
+	})
+	jsTransaction.Set("oncomplete", onComplete)
+	jsTransaction.Set("onerror", onError)
+	jsTransaction.Set("onabort", onError)
+}
+
+// jsTry calls fn and returns any JavaScript exception that it throws as
+// an error.
+func jsTry(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			jsErr, ok := r.(js.Error)
+			if !ok {
+				panic(r)
+			}
+			err = jsErr
+		}
+	}()
+	fn()
+	return nil
+}
+
+func jsInt64(value js.Value) int64 {
+	if value.Type() != js.TypeNumber {
+		return 0
+	}
+	return int64(value.Float())
+}
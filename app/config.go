@@ -1,28 +1,50 @@
 package app
 
-import "github.com/mokiat/lacking/app"
+import (
+	"time"
+
+	"github.com/mokiat/lacking/app"
+)
 
 // NewConfig creates a new Config object that contains the minimum
 // required settings.
 func NewConfig(canvasID string) *Config {
 	return &Config{
-		canvasID:      canvasID,
-		cursorVisible: true,
-		audioEnabled:  true,
+		canvasID:       canvasID,
+		cursorVisible:  true,
+		audioEnabled:   true,
+		maxUpdateSteps: 5,
+		passthrough:    DefaultKeyboardPassthrough,
+		storageName:    "lacking",
+		maxFetches:     6,
 	}
 }
 
 // Config represents an application window configuration.
 type Config struct {
-	canvasID      string
-	title         *string
-	width         *int
-	height        *int
-	fullscreen    bool
-	cursorVisible bool
-	cursor        *app.CursorDefinition
-	glExtensions  []string
-	audioEnabled  bool
+	canvasID        string
+	title           *string
+	width           *int
+	height          *int
+	fullscreen      bool
+	autoResize      bool
+	keyboardLock    bool
+	maxPixelRatio   float64
+	cursorVisible   bool
+	cursor          *app.CursorDefinition
+	rawCursorMotion bool
+	passthrough     KeyboardPassthroughFunc
+	glExtensions    []string
+	restoreContext  bool
+	renderOnDemand  bool
+	maxFrameRate    int
+	updateInterval  time.Duration
+	taskQueueLimit  int
+	storageName     string
+	maxFetches      int
+	fetchCacheName  string
+	maxUpdateSteps  int
+	audioEnabled    bool
 }
 
 // Title returns the title of the application window.
@@ -71,11 +93,57 @@ func (c *Config) Fullscreen() bool {
 }
 
 // SetFullscreen specifies whether the application window should
-// be displayed in fullscreen mode.
+// be displayed in fullscreen mode. The canvas is stretched over the page
+// and browser fullscreen is requested on the first user interaction,
+// since browsers do not allow it to be entered otherwise.
 func (c *Config) SetFullscreen(fullscreen bool) {
 	c.fullscreen = fullscreen
 }
 
+// FullscreenKeyboardLock returns whether system keyboard shortcuts will
+// be captured while in fullscreen mode.
+func (c *Config) FullscreenKeyboardLock() bool {
+	return c.keyboardLock
+}
+
+// SetFullscreenKeyboardLock specifies whether system keyboard shortcuts
+// (e.g. Escape or Alt+Tab) should be delivered to the application while in
+// fullscreen mode. This relies on the Keyboard Lock API, which is not
+// available in all browsers.
+func (c *Config) SetFullscreenKeyboardLock(lock bool) {
+	c.keyboardLock = lock
+}
+
+// AutoResize returns whether the canvas backing store will be resized
+// to follow the size of the canvas on the page.
+func (c *Config) AutoResize() bool {
+	return c.autoResize
+}
+
+// SetAutoResize specifies whether the canvas backing store should be
+// resized to follow the size of the canvas on the page, scaled by the
+// device pixel ratio. When enabled, the configured width and height
+// specify the CSS size of the canvas. Fullscreen mode always resizes
+// automatically.
+func (c *Config) SetAutoResize(autoResize bool) {
+	c.autoResize = autoResize
+}
+
+// MaxPixelRatio returns the maximum device pixel ratio that will be used
+// when sizing the canvas backing store. A value of zero means that the
+// device pixel ratio is not capped.
+func (c *Config) MaxPixelRatio() float64 {
+	return c.maxPixelRatio
+}
+
+// SetMaxPixelRatio specifies the maximum device pixel ratio that should
+// be used when sizing the canvas backing store. This can be used to limit
+// the rendering cost on high-density displays. A value of zero means that
+// the device pixel ratio is not capped.
+func (c *Config) SetMaxPixelRatio(ratio float64) {
+	c.maxPixelRatio = ratio
+}
+
 // CursorVisible returns whether the cursor will be shown
 // when hovering over the window.
 func (c *Config) CursorVisible() bool {
@@ -99,12 +167,169 @@ func (c *Config) SetCursor(definition *app.CursorDefinition) {
 	c.cursor = definition
 }
 
+// UnadjustedCursorMovement returns whether mouse movement should be
+// reported without OS-level acceleration while the cursor is locked.
+func (c *Config) UnadjustedCursorMovement() bool {
+	return c.rawCursorMotion
+}
+
+// SetUnadjustedCursorMovement specifies whether mouse movement should be
+// reported without OS-level acceleration while the cursor is locked.
+// Browsers that do not support this fall back to regular movement.
+func (c *Config) SetUnadjustedCursorMovement(unadjusted bool) {
+	c.rawCursorMotion = unadjusted
+}
+
+// KeyboardPassthrough returns the function that decides which keyboard
+// events are left to the browser.
+func (c *Config) KeyboardPassthrough() KeyboardPassthroughFunc {
+	return c.passthrough
+}
+
+// SetKeyboardPassthrough specifies the function that decides whether a
+// keyboard event that was not consumed by the controller should be
+// handled by the browser. By default, DefaultKeyboardPassthrough is used.
+// Specifying nil is equivalent to NoKeyboardPassthrough.
+func (c *Config) SetKeyboardPassthrough(passthrough KeyboardPassthroughFunc) {
+	if passthrough == nil {
+		passthrough = NoKeyboardPassthrough
+	}
+	c.passthrough = passthrough
+}
+
+// StorageName returns the name under which persistent data is stored.
+func (c *Config) StorageName() string {
+	return c.storageName
+}
+
+// SetStorageName specifies the name of the IndexedDB database and the
+// prefix of localStorage keys that are used by Window.Storage. Different
+// applications that are hosted on the same origin should use different
+// names. By default, "lacking" is used.
+func (c *Config) SetStorageName(name string) {
+	c.storageName = name
+}
+
+// MaxParallelFetches returns the maximum number of requests that
+// Window.Fetcher runs at the same time.
+func (c *Config) MaxParallelFetches() int {
+	return c.maxFetches
+}
+
+// SetMaxParallelFetches specifies the maximum number of requests that
+// Window.Fetcher runs at the same time. Further requests are queued. A
+// value of zero means that the number is not limited. By default, six
+// requests are run in parallel.
+func (c *Config) SetMaxParallelFetches(limit int) {
+	c.maxFetches = max(limit, 0)
+}
+
+// FetchCacheName returns the name of the cache that is used by
+// Window.Fetcher.
+func (c *Config) FetchCacheName() string {
+	return c.fetchCacheName
+}
+
+// SetFetchCacheName specifies the name of the Cache Storage cache in which
+// Window.Fetcher keeps resources that have a known hash. Changing the name
+// (e.g. with each release) starts with an empty cache. By default, the
+// name is empty and resources are not cached.
+func (c *Config) SetFetchCacheName(name string) {
+	c.fetchCacheName = name
+}
+
 // AddGLExtension adds a new OpenGL extension that should be
 // enabled when creating the OpenGL context.
 func (c *Config) AddGLExtension(name string) {
 	c.glExtensions = append(c.glExtensions, name)
 }
 
+// ContextRestoration returns whether graphics resources will be recreated
+// automatically when the WebGL context is restored after being lost.
+func (c *Config) ContextRestoration() bool {
+	return c.restoreContext
+}
+
+// SetContextRestoration specifies whether graphics resources should be
+// recreated automatically when the WebGL context is restored after being
+// lost. This requires that the creation data of all resources is kept in
+// memory. Content that was written to resources after their creation is
+// not restored. When disabled, the controller should implement
+// GraphicsContextController and recreate its resources itself.
+func (c *Config) SetContextRestoration(restore bool) {
+	c.restoreContext = restore
+}
+
+// RenderOnDemand returns whether the controller will only be asked to
+// render when the window has been invalidated.
+func (c *Config) RenderOnDemand() bool {
+	return c.renderOnDemand
+}
+
+// SetRenderOnDemand specifies whether the controller should only be asked
+// to render when the window has been invalidated, either explicitly through
+// app.Window.Invalidate or implicitly by input, resizing, scheduled tasks
+// or fixed updates. This reduces power consumption for applications that
+// are mostly static.
+func (c *Config) SetRenderOnDemand(onDemand bool) {
+	c.renderOnDemand = onDemand
+}
+
+// MaxFrameRate returns the maximum number of frames per second. A value
+// of zero means that the frame rate is not limited.
+func (c *Config) MaxFrameRate() int {
+	return c.maxFrameRate
+}
+
+// SetMaxFrameRate specifies the maximum number of frames per second.
+// Animation frames that arrive sooner are skipped entirely. A value of
+// zero means that the frame rate follows the display refresh rate.
+func (c *Config) SetMaxFrameRate(fps int) {
+	c.maxFrameRate = max(fps, 0)
+}
+
+// FixedUpdateInterval returns the interval at which fixed updates will
+// be performed. A value of zero means that fixed updates are disabled.
+func (c *Config) FixedUpdateInterval() time.Duration {
+	return c.updateInterval
+}
+
+// SetFixedUpdateInterval specifies the interval at which fixed updates
+// should be performed. Real time is accumulated between frames and as many
+// fixed updates as fit are dispatched to a controller that implements
+// FixedUpdateController before each render, regardless of the display
+// refresh rate. A value of zero disables fixed updates.
+func (c *Config) SetFixedUpdateInterval(interval time.Duration) {
+	c.updateInterval = interval
+}
+
+// MaxUpdateSteps returns the maximum number of fixed updates that will
+// be performed per frame.
+func (c *Config) MaxUpdateSteps() int {
+	return c.maxUpdateSteps
+}
+
+// SetMaxUpdateSteps specifies the maximum number of fixed updates that
+// should be performed per frame. If the application falls behind by
+// more, then the excess time is dropped so that slow updates do not cause
+// an ever increasing backlog.
+func (c *Config) SetMaxUpdateSteps(steps int) {
+	c.maxUpdateSteps = max(steps, 1)
+}
+
+// TaskQueueLimit returns the maximum number of tasks that can be
+// pending. A value of zero means that the number is not limited.
+func (c *Config) TaskQueueLimit() int {
+	return c.taskQueueLimit
+}
+
+// SetTaskQueueLimit specifies the maximum number of tasks that can be
+// pending. Tasks that are scheduled beyond the limit are dropped. A value
+// of zero means that the number is not limited.
+func (c *Config) SetTaskQueueLimit(limit int) {
+	c.taskQueueLimit = max(limit, 0)
+}
+
 // AudioEnabled returns whether audio is enabled for this application.
 func (c *Config) AudioEnabled() bool {
 	return c.audioEnabled
@@ -1,15 +1,184 @@
+//go:build js && wasm
+
 package app
 
-import "github.com/mokiat/lacking/app"
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"log/slog"
+	"strings"
+	"syscall/js"
+
+	"github.com/mokiat/lacking/app"
+)
+
+// maxCursorSize is the largest cursor image size, in CSS pixels, that is
+// accepted by all major browsers.
+const maxCursorSize = 128
+
+// SystemCursor is one of the standard cursors that are provided by the
+// browser.
+type SystemCursor string
+
+const (
+	SystemCursorDefault    SystemCursor = "default"
+	SystemCursorPointer    SystemCursor = "pointer"
+	SystemCursorText       SystemCursor = "text"
+	SystemCursorCrosshair  SystemCursor = "crosshair"
+	SystemCursorMove       SystemCursor = "move"
+	SystemCursorGrab       SystemCursor = "grab"
+	SystemCursorGrabbing   SystemCursor = "grabbing"
+	SystemCursorNotAllowed SystemCursor = "not-allowed"
+	SystemCursorWait       SystemCursor = "wait"
+	SystemCursorProgress   SystemCursor = "progress"
+	SystemCursorHelp       SystemCursor = "help"
+	SystemCursorZoomIn     SystemCursor = "zoom-in"
+	SystemCursorZoomOut    SystemCursor = "zoom-out"
+	SystemCursorResizeEW   SystemCursor = "ew-resize"
+	SystemCursorResizeNS   SystemCursor = "ns-resize"
+	SystemCursorResizeNESW SystemCursor = "nesw-resize"
+	SystemCursorResizeNWSE SystemCursor = "nwse-resize"
+	SystemCursorResizeCol  SystemCursor = "col-resize"
+	SystemCursorResizeRow  SystemCursor = "row-resize"
+)
+
+// CursorImage is a cursor image for a specific device pixel ratio.
+type CursorImage struct {
+
+	// Image holds the pixels of the cursor.
+	Image image.Image
+
+	// Scale is the device pixel ratio for which the image is intended
+	// (e.g. 2.0 for an image that is twice the size of the cursor in CSS
+	// pixels). Zero is treated as 1.0.
+	Scale float64
+}
+
+// ImageCursorDefinition describes a cursor that is created from in-memory
+// images.
+type ImageCursorDefinition struct {
+
+	// Images holds the variants of the cursor for different device pixel
+	// ratios. The browser picks the most appropriate one. There needs to
+	// be at least one image.
+	Images []CursorImage
+
+	// HotspotX is the horizontal position of the cursor hotspot, in CSS
+	// pixels. It is clamped to the bounds of the cursor.
+	HotspotX int
+
+	// HotspotY is the vertical position of the cursor hotspot, in CSS
+	// pixels. It is clamped to the bounds of the cursor.
+	HotspotY int
+
+	// Fallback is the cursor that is used if the browser rejects the
+	// images. It defaults to SystemCursorDefault.
+	Fallback SystemCursor
+}
 
 var _ app.Cursor = (*Cursor)(nil)
 
 type Cursor struct {
-	path     string
-	hotspotX int
-	hotspotY int
+	style string
 }
 
 func (c *Cursor) Destroy() {
-	c.path = ""
+	c.style = ""
+}
+
+func newPathCursor(definition app.CursorDefinition) *Cursor {
+	return &Cursor{
+		style: fmt.Sprintf("url(%s) %d %d, auto",
+			definition.Path, max(definition.HotspotX, 0), max(definition.HotspotY, 0)),
+	}
+}
+
+func newSystemCursor(cursor SystemCursor) *Cursor {
+	return &Cursor{
+		style: string(cursor),
+	}
+}
+
+func newImageCursor(definition ImageCursorDefinition) (*Cursor, error) {
+	if len(definition.Images) == 0 {
+		return nil, fmt.Errorf("cursor has no images")
+	}
+	fallback := definition.Fallback
+	if fallback == "" {
+		fallback = SystemCursorDefault
+	}
+
+	var (
+		width, height int
+		candidates    = make([]string, len(definition.Images))
+		primaryURL    string
+		primaryScale  = 0.0
+	)
+	for i, cursorImage := range definition.Images {
+		if cursorImage.Image == nil {
+			return nil, fmt.Errorf("cursor image %d is nil", i)
+		}
+		scale := cursorImage.Scale
+		if scale <= 0.0 {
+			scale = 1.0
+		}
+		url, err := imageDataURL(cursorImage.Image)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding cursor image: %w", err)
+		}
+		candidates[i] = fmt.Sprintf("url(%q) %gx", url, scale)
+
+		// Browsers that lack image-set support use the image that is
+		// closest to 1x.
+		if primaryURL == "" || abs(scale-1.0) < abs(primaryScale-1.0) {
+			bounds := cursorImage.Image.Bounds()
+			width = int(float64(bounds.Dx()) / scale)
+			height = int(float64(bounds.Dy()) / scale)
+			primaryURL = url
+			primaryScale = scale
+		}
+	}
+	if width > maxCursorSize || height > maxCursorSize {
+		logger.Warn("Cursor is larger than browsers support",
+			slog.Int("width", width),
+			slog.Int("height", height),
+		)
+	}
+
+	// NOTE: Browsers ignore cursors with a hotspot outside of the image.
+	hotspotX := min(max(definition.HotspotX, 0), max(width-1, 0))
+	hotspotY := min(max(definition.HotspotY, 0), max(height-1, 0))
+
+	imageSet := strings.Join(candidates, ", ")
+	styles := []string{
+		fmt.Sprintf("image-set(%s) %d %d, %s", imageSet, hotspotX, hotspotY, fallback),
+		fmt.Sprintf("-webkit-image-set(%s) %d %d, %s", imageSet, hotspotX, hotspotY, fallback),
+		fmt.Sprintf("url(%q) %d %d, %s", primaryURL, hotspotX, hotspotY, fallback),
+	}
+	for _, style := range styles {
+		if isCSSSupported("cursor", style) {
+			return &Cursor{style: style}, nil
+		}
+	}
+	return &Cursor{style: styles[len(styles)-1]}, nil
+}
+
+// imageDataURL encodes the image as a PNG data URL.
+func imageDataURL(img image.Image) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func isCSSSupported(property, value string) bool {
+	jsCSS := js.Global().Get("CSS")
+	if jsCSS.IsUndefined() || jsCSS.Get("supports").IsUndefined() {
+		return false
+	}
+	return jsCSS.Call("supports", property, value).Bool()
 }
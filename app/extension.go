@@ -0,0 +1,143 @@
+//go:build js && wasm
+
+package app
+
+import (
+	"time"
+
+	"github.com/mokiat/lacking/app"
+)
+
+// Window extends app.Window with functionality that is specific to
+// the browser. The app.Window that is passed to the app.Controller
+// can be type asserted to this interface.
+type Window interface {
+	app.Window
+
+	// Pointer returns the state of the active pointer that is reported
+	// with the specified app.MouseEvent index.
+	Pointer(index int) (Pointer, bool)
+
+	// AllGamepads returns all gamepad slots. Unlike Gamepads, which only
+	// returns the first four, this includes additional slots that are
+	// allocated when more gamepads are connected. Slot indices match
+	// app.GamepadEvent.Index and a gamepad that reconnects is assigned its
+	// previous slot, if it is still free.
+	AllGamepads() []app.Gamepad
+
+	// CreateSystemCursor creates a cursor that uses one of the standard
+	// browser cursors.
+	CreateSystemCursor(cursor SystemCursor) app.Cursor
+
+	// CreateImageCursor creates a cursor from in-memory images.
+	CreateImageCursor(definition ImageCursorDefinition) (app.Cursor, error)
+
+	// Storage returns the persistent storage of the application.
+	Storage() Storage
+
+	// Fetcher returns the downloader of remote resources.
+	Fetcher() *Fetcher
+
+	// Modifiers returns the keyboard modifiers that were active during the
+	// most recent keyboard or pointer event. During event callbacks, these
+	// are the modifiers of the event that is being dispatched.
+	Modifiers() KeyModifiers
+
+	// Fullscreen returns whether the canvas is displayed in browser
+	// fullscreen mode.
+	Fullscreen() bool
+
+	// SetFullscreen requests that the canvas enter or leave browser
+	// fullscreen mode. Browsers only allow fullscreen to be entered in
+	// response to a user gesture, so the request may be applied on the next
+	// user interaction.
+	SetFullscreen(fullscreen bool)
+
+	// InterpolationAlpha returns the fraction of the fixed update interval
+	// that has elapsed since the last fixed update, in the range [0.0, 1.0).
+	// It can be used during rendering to interpolate between the previous
+	// and the current simulation state. It is zero when fixed updates are
+	// disabled.
+	InterpolationAlpha() float64
+
+	// ScheduleWithPriority queues the specified function to be run on the
+	// main loop with the specified priority. It returns false if the task
+	// was rejected because the queue limit was reached or the priority is
+	// not valid.
+	ScheduleWithPriority(fn func(), priority TaskPriority) bool
+
+	// TaskQueueStats returns information about the scheduled tasks.
+	TaskQueueStats() TaskQueueStats
+
+	// TextInputEnabled returns whether text input is enabled.
+	TextInputEnabled() bool
+
+	// SetTextInputEnabled specifies whether the application expects text
+	// to be typed (e.g. a text field has focus). While enabled, typed text
+	// is delivered with keyboard layout and IME support and controllers
+	// that implement TextCompositionController receive preedit updates.
+	SetTextInputEnabled(enabled bool)
+
+	// SetTextInputPosition specifies the canvas position of the text
+	// cursor, which is where IMEs show their candidate window.
+	SetTextInputPosition(x, y int)
+}
+
+// GraphicsContextController can optionally be implemented by an
+// app.Controller to be notified when the WebGL context is lost and
+// restored. Rendering is paused while the context is lost. Once it is
+// restored, resources that were not recreated automatically (see
+// Config.SetContextRestoration) need to be recreated by the controller.
+type GraphicsContextController interface {
+
+	// OnContextLost is called when the WebGL context is lost.
+	OnContextLost(window app.Window)
+
+	// OnContextRestored is called when the WebGL context is restored and
+	// any automatic resource restoration has completed.
+	OnContextRestored(window app.Window)
+}
+
+// LifecycleController can optionally be implemented by an app.Controller
+// to be notified when the page is hidden or shown and when it loses or
+// gains focus. Audio is suspended automatically while the page is hidden
+// and held keys and buttons are released when focus is lost.
+type LifecycleController interface {
+
+	// OnVisibilityChange is called when the page becomes hidden (e.g. the
+	// tab is switched or the browser is minimized) or visible again.
+	OnVisibilityChange(window app.Window, visible bool)
+
+	// OnFocusChange is called when the page loses or gains focus.
+	OnFocusChange(window app.Window, focused bool)
+}
+
+// FixedUpdateController can optionally be implemented by an app.Controller
+// to receive updates at the fixed interval that is configured through
+// Config.SetFixedUpdateInterval.
+type FixedUpdateController interface {
+
+	// OnFixedUpdate is called zero or more times before each render with
+	// the fixed update interval.
+	OnFixedUpdate(window app.Window, elapsedTime time.Duration)
+}
+
+// TextCompositionController can optionally be implemented by an
+// app.Controller to receive the preedit state of IME text compositions
+// while text input is enabled.
+type TextCompositionController interface {
+
+	// OnTextComposition is called when a text composition starts, changes
+	// or ends.
+	OnTextComposition(window app.Window, event TextCompositionEvent)
+}
+
+// FileController can optionally be implemented by an app.Controller to
+// receive files that are dropped onto the canvas or pasted from the
+// clipboard. Files are only accepted if the controller implements it.
+type FileController interface {
+
+	// OnFileEvent is called through the task queue once the contents of
+	// the dropped or pasted files have been read.
+	OnFileEvent(window app.Window, event FileEvent)
+}
@@ -0,0 +1,442 @@
+//go:build js && wasm
+
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"syscall/js"
+)
+
+// ErrHashMismatch is reported when fetched data does not match the
+// expected hash of a FetchRequest.
+var ErrHashMismatch = errors.New("hash mismatch")
+
+// FetchRequest describes a remote resource that should be fetched.
+type FetchRequest struct {
+
+	// URL is the location of the resource. Relative URLs are resolved
+	// against the page.
+	URL string
+
+	// SHA256 is the optional hex-encoded SHA-256 hash of the resource. If
+	// specified, the fetched data is verified against it and, if a cache
+	// is configured (see Config.SetFetchCacheName), the data is stored in
+	// and served from the cache using the hash as key.
+	SHA256 string
+
+	// OnProgress is optionally called on the loop goroutine as the
+	// resource is being downloaded.
+	OnProgress func(progress FetchProgress)
+}
+
+// FetchProgress describes how much of a resource has been downloaded.
+type FetchProgress struct {
+
+	// Loaded is the number of bytes that have been received.
+	Loaded int64
+
+	// Total is the size of the resource in bytes. It is zero if the server
+	// did not report the size.
+	Total int64
+}
+
+// Fraction returns the downloaded portion of the resource in the range
+// [0.0, 1.0]. It returns zero if the size of the resource is unknown.
+func (p FetchProgress) Fraction() float64 {
+	if p.Total <= 0 {
+		return 0.0
+	}
+	return min(float64(p.Loaded)/float64(p.Total), 1.0)
+}
+
+func newFetcher(schedule func(fn func()), limit int, cacheName string) *Fetcher {
+	return &Fetcher{
+		schedule:  schedule,
+		limit:     limit,
+		cacheName: cacheName,
+	}
+}
+
+// Fetcher downloads remote resources through the fetch API. A limited
+// number of requests are run in parallel and the rest are queued. All
+// callbacks are delivered on the loop goroutine through the task queue.
+type Fetcher struct {
+	schedule  func(fn func())
+	limit     int
+	cacheName string
+
+	mu     sync.Mutex
+	active int
+	queue  []*fetchJob
+}
+
+// Fetch downloads the specified resource and passes its data to callback.
+// Cancelling ctx aborts the request, in which case callback receives the
+// context error.
+func (f *Fetcher) Fetch(ctx context.Context, request FetchRequest, callback func(data []byte, err error)) {
+	job := &fetchJob{
+		fetcher:  f,
+		ctx:      ctx,
+		request:  request,
+		callback: callback,
+		abort:    js.Null(),
+	}
+	job.stop = context.AfterFunc(ctx, job.cancel)
+
+	f.mu.Lock()
+	f.queue = append(f.queue, job)
+	f.mu.Unlock()
+	f.startNext()
+}
+
+// Pending returns the number of requests that are in progress or queued.
+func (f *Fetcher) Pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.active + len(f.queue)
+}
+
+func (f *Fetcher) startNext() {
+	for {
+		f.mu.Lock()
+		if len(f.queue) == 0 || (f.limit > 0 && f.active >= f.limit) {
+			f.mu.Unlock()
+			return
+		}
+		job := f.queue[0]
+		f.queue = f.queue[1:]
+		f.active++
+		f.mu.Unlock()
+		job.start()
+	}
+}
+
+// dequeue removes a job that has not been started yet. It returns false
+// if the job was already started.
+func (f *Fetcher) dequeue(job *fetchJob) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	index := -1
+	for i, queued := range f.queue {
+		if queued == job {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return false
+	}
+	f.queue = append(f.queue[:index], f.queue[index+1:]...)
+	return true
+}
+
+type fetchJob struct {
+	fetcher  *Fetcher
+	ctx      context.Context
+	request  FetchRequest
+	callback func(data []byte, err error)
+	stop     func() bool
+
+	mu               sync.Mutex
+	abort            js.Value
+	started          bool
+	done             bool
+	progressPending  bool
+	progress         FetchProgress
+	expectedChecksum []byte
+}
+
+func (j *fetchJob) start() {
+	j.mu.Lock()
+	j.started = true
+	j.mu.Unlock()
+
+	if err := j.ctx.Err(); err != nil {
+		j.finish(nil, err)
+		return
+	}
+	if j.request.SHA256 != "" {
+		checksum, err := hex.DecodeString(j.request.SHA256)
+		if err != nil || len(checksum) != sha256.Size {
+			j.finish(nil, fmt.Errorf("invalid SHA-256 hash %q", j.request.SHA256))
+			return
+		}
+		j.expectedChecksum = checksum
+	}
+	if j.cacheable() {
+		j.fetchFromCache()
+	} else {
+		j.fetchFromNetwork()
+	}
+}
+
+func (j *fetchJob) cancel() {
+	if j.fetcher.dequeue(j) {
+		j.finish(nil, j.ctx.Err())
+		return
+	}
+	j.mu.Lock()
+	jsAbort := j.abort
+	j.mu.Unlock()
+	if !jsAbort.IsNull() {
+		jsAbort.Call("abort")
+	}
+}
+
+func (j *fetchJob) cacheable() bool {
+	if j.fetcher.cacheName == "" || j.expectedChecksum == nil {
+		return false
+	}
+	return !js.Global().Get("caches").IsUndefined()
+}
+
+func (j *fetchJob) cacheKey() string {
+	return "lacking-cache/sha256/" + strings.ToLower(j.request.SHA256)
+}
+
+func (j *fetchJob) fetchFromCache() {
+	jsCaches := js.Global().Get("caches")
+	awaitPromise(jsCaches.Call("open", j.fetcher.cacheName), func(jsCache js.Value, err error) {
+		if err != nil {
+			logger.Warn("Failed to open fetch cache",
+				slog.String("error", err.Error()),
+			)
+			j.fetchFromNetwork()
+			return
+		}
+		awaitPromise(jsCache.Call("match", j.cacheKey()), func(jsResponse js.Value, err error) {
+			if err != nil || jsResponse.IsUndefined() {
+				j.fetchFromNetwork()
+				return
+			}
+			j.readResponse(jsResponse, func(data []byte) {
+				if !j.verify(data) {
+					// The cached entry is corrupt, so it is replaced.
+					j.fetchFromNetwork()
+					return
+				}
+				j.finish(data, nil)
+			})
+		})
+	})
+}
+
+func (j *fetchJob) fetchFromNetwork() {
+	if err := j.ctx.Err(); err != nil {
+		j.finish(nil, err)
+		return
+	}
+	jsAbort := js.Global().Get("AbortController").New()
+	j.mu.Lock()
+	j.abort = jsAbort
+	j.mu.Unlock()
+
+	jsPromise := js.Global().Call("fetch", j.request.URL, map[string]any{
+		"signal": jsAbort.Get("signal"),
+	})
+	awaitPromise(jsPromise, func(jsResponse js.Value, err error) {
+		if err != nil {
+			j.fail(err)
+			return
+		}
+		if !jsResponse.Get("ok").Bool() {
+			j.fail(fmt.Errorf("unexpected status code %d", jsResponse.Get("status").Int()))
+			return
+		}
+		j.readResponse(jsResponse, func(data []byte) {
+			if !j.verify(data) {
+				j.fail(ErrHashMismatch)
+				return
+			}
+			if j.cacheable() {
+				j.store(data)
+			}
+			j.finish(data, nil)
+		})
+	})
+}
+
+// maxFetchPreallocation is the largest buffer that is reserved upfront
+// based on the Content-Length of a response.
+const maxFetchPreallocation = 64 << 20
+
+// readResponse reads the body of the response in chunks, so that progress
+// can be reported, and passes the complete data to fn.
+func (j *fetchJob) readResponse(jsResponse js.Value, fn func(data []byte)) {
+	var total int64
+	if jsLength := jsResponse.Get("headers").Call("get", "Content-Length"); !jsLength.IsNull() {
+		fmt.Sscan(jsLength.String(), &total)
+	}
+	// NOTE: The Content-Length header is not trusted for the allocation,
+	// since a wrong value would reserve memory before any data arrives.
+	// Larger responses grow the buffer as chunks are received.
+	var data []byte
+	if total > 0 {
+		data = make([]byte, 0, min(total, maxFetchPreallocation))
+	}
+
+	jsBody := jsResponse.Get("body")
+	if jsBody.IsUndefined() || jsBody.IsNull() {
+		// Older browsers do not support streaming response bodies.
+		awaitPromise(jsResponse.Call("arrayBuffer"), func(jsBuffer js.Value, err error) {
+			if err != nil {
+				j.fail(err)
+				return
+			}
+			jsData := js.Global().Get("Uint8Array").New(jsBuffer)
+			data = make([]byte, jsData.Length())
+			js.CopyBytesToGo(data, jsData)
+			j.reportProgress(int64(len(data)), total)
+			fn(data)
+		})
+		return
+	}
+
+	jsReader := jsBody.Call("getReader")
+	var readChunk func()
+	readChunk = func() {
+		awaitPromise(jsReader.Call("read"), func(jsChunk js.Value, err error) {
+			if err != nil {
+				j.fail(err)
+				return
+			}
+			if jsChunk.Get("done").Bool() {
+				fn(data)
+				return
+			}
+			jsValue := jsChunk.Get("value")
+			offset := len(data)
+			data = append(data, make([]byte, jsValue.Length())...)
+			js.CopyBytesToGo(data[offset:], jsValue)
+			j.reportProgress(int64(len(data)), total)
+			readChunk()
+		})
+	}
+	readChunk()
+}
+
+// reportProgress schedules a progress callback. Progress updates that
+// arrive while a previous one is still pending are coalesced.
+func (j *fetchJob) reportProgress(loaded, total int64) {
+	if j.request.OnProgress == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	// NOTE: Compressed responses report the compressed size, which can be
+	// smaller than the received data.
+	j.progress = FetchProgress{
+		Loaded: loaded,
+	}
+	if total > 0 {
+		j.progress.Total = max(total, loaded)
+	}
+	if j.progressPending {
+		return
+	}
+	j.progressPending = true
+	j.fetcher.schedule(func() {
+		j.mu.Lock()
+		progress := j.progress
+		done := j.done
+		j.progressPending = false
+		j.mu.Unlock()
+		if !done {
+			j.request.OnProgress(progress)
+		}
+	})
+}
+
+func (j *fetchJob) verify(data []byte) bool {
+	if j.expectedChecksum == nil {
+		return true
+	}
+	checksum := sha256.Sum256(data)
+	return string(checksum[:]) == string(j.expectedChecksum)
+}
+
+func (j *fetchJob) store(data []byte) {
+	jsData := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(jsData, data)
+	jsResponse := js.Global().Get("Response").New(jsData)
+	awaitPromise(js.Global().Get("caches").Call("open", j.fetcher.cacheName), func(jsCache js.Value, err error) {
+		if err != nil {
+			return
+		}
+		awaitPromise(jsCache.Call("put", j.cacheKey(), jsResponse), func(_ js.Value, err error) {
+			if err != nil {
+				logger.Warn("Failed to store fetched data in cache",
+					slog.String("url", j.request.URL),
+					slog.String("error", err.Error()),
+				)
+			}
+		})
+	})
+}
+
+// fail finishes the job with the specified error, unless the context was
+// cancelled, in which case the context error is reported instead.
+func (j *fetchJob) fail(err error) {
+	if ctxErr := j.ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	}
+	j.finish(nil, fmt.Errorf("failed to fetch %q: %w", j.request.URL, err))
+}
+
+func (j *fetchJob) finish(data []byte, err error) {
+	j.mu.Lock()
+	if j.done {
+		j.mu.Unlock()
+		return
+	}
+	j.done = true
+	started := j.started
+	if ctxErr := j.ctx.Err(); ctxErr != nil && err == nil {
+		// The request was cancelled while the data was being verified or
+		// read from the cache.
+		data, err = nil, ctxErr
+	}
+	j.abort = js.Null()
+	j.mu.Unlock()
+
+	j.stop()
+	j.fetcher.schedule(func() {
+		j.callback(data, err)
+	})
+	if started {
+		j.fetcher.mu.Lock()
+		j.fetcher.active--
+		j.fetcher.mu.Unlock()
+		j.fetcher.startNext()
+	}
+}
+
+// awaitPromise calls fn once the specified promise is resolved or
+// rejected.
+func awaitPromise(jsPromise js.Value, fn func(result js.Value, err error)) {
+	var onResolve, onReject js.Func
+	onResolve = js.FuncOf(func(this js.Value, args []js.Value) any {
+		onResolve.Release()
+		onReject.Release()
+		var result js.Value
+		if len(args) > 0 {
+			result = args[0]
+		}
+		fn(result, nil)
+		return nil
+	})
+	onReject = js.FuncOf(func(this js.Value, args []js.Value) any {
+		onResolve.Release()
+		onReject.Release()
+		fn(js.Undefined(), errors.New(jsErrorMessage(args)))
+		return nil
+	})
+	jsPromise.Call("then", onResolve, onReject)
+}
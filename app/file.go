@@ -0,0 +1,261 @@
+//go:build js && wasm
+
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"syscall/js"
+
+	"github.com/mokiat/lacking/app"
+)
+
+// FileSource specifies how files were provided to the application.
+type FileSource int
+
+const (
+	// FileSourceDrop indicates that the files were dragged and dropped
+	// onto the canvas.
+	FileSourceDrop FileSource = iota
+
+	// FileSourcePaste indicates that the files (usually an image) were
+	// pasted from the clipboard.
+	FileSourcePaste
+)
+
+// File is a file that was provided by the user.
+type File struct {
+
+	// Name is the name of the file, without any path information. Pasted
+	// images usually have a generic name (e.g. "image.png").
+	Name string
+
+	// Type is the MIME type of the file as reported by the browser (e.g.
+	// "image/png"). It can be empty if the browser does not recognize the
+	// file extension, as is the case for "model/gltf-binary" on most
+	// platforms.
+	Type string
+
+	// Data holds the contents of the file.
+	Data []byte
+}
+
+// FileEvent is delivered when the user drops or pastes files.
+type FileEvent struct {
+
+	// Source specifies how the files were provided.
+	Source FileSource
+
+	// X is the horizontal position on the canvas at which the files were
+	// dropped. It is zero for pasted files.
+	X int
+
+	// Y is the vertical position on the canvas at which the files were
+	// dropped. It is zero for pasted files.
+	Y int
+
+	// Files holds the files that could be read. Files that failed to load
+	// are logged and omitted.
+	Files []File
+}
+
+func (l *loop) onJSDragOver(this js.Value, args []js.Value) any {
+	event := args[0]
+	jsDataTransfer := event.Get("dataTransfer")
+	if !hasDataTransferFiles(jsDataTransfer) {
+		return nil
+	}
+	// NOTE: Files need to be accepted during dragover for the drop event to
+	// be fired. Otherwise the browser would navigate away to the file.
+	event.Call("preventDefault")
+	if _, ok := l.controller.(FileController); ok {
+		jsDataTransfer.Set("dropEffect", "copy")
+	} else {
+		jsDataTransfer.Set("dropEffect", "none")
+	}
+	return nil
+}
+
+func (l *loop) onJSDrop(this js.Value, args []js.Value) any {
+	event := args[0]
+	jsDataTransfer := event.Get("dataTransfer")
+	if !hasDataTransferFiles(jsDataTransfer) {
+		return nil
+	}
+	event.Call("preventDefault")
+	if _, ok := l.controller.(FileController); !ok {
+		return nil
+	}
+	x, y := l.mousePosition(event)
+	l.readFiles(jsDataTransfer.Get("files"), FileEvent{
+		Source: FileSourceDrop,
+		X:      x,
+		Y:      y,
+	})
+	return nil
+}
+
+func (l *loop) onJSPaste(this js.Value, args []js.Value) any {
+	event := args[0]
+	if !l.isPasteTarget(event.Get("target")) {
+		// The paste is meant for other elements on the page.
+		return nil
+	}
+	jsClipboardData := event.Get("clipboardData")
+	if jsClipboardData.IsUndefined() || jsClipboardData.IsNull() {
+		return nil
+	}
+
+	// NOTE: While text input is enabled, pasted text reaches the
+	// controller through the input event of the text input element, so
+	// the default behavior must not be prevented.
+	if !l.textInput.Enabled() {
+		text := jsClipboardData.Call("getData", "text/plain").String()
+		if text != "" {
+			event.Call("preventDefault")
+			l.Schedule(func() {
+				l.controller.OnClipboardEvent(l, app.ClipboardEvent{
+					Text: text,
+				})
+			})
+		}
+	}
+
+	if _, ok := l.controller.(FileController); !ok {
+		return nil
+	}
+	jsFiles := jsClipboardData.Get("files")
+	if jsFiles.IsUndefined() || jsFiles.IsNull() || jsFiles.Length() == 0 {
+		return nil
+	}
+	if !l.textInput.Enabled() {
+		event.Call("preventDefault")
+	}
+	l.readFiles(jsFiles, FileEvent{
+		Source: FileSourcePaste,
+	})
+	return nil
+}
+
+// isPasteTarget returns whether a paste into the specified element is
+// meant for the application. The listener is registered on the document,
+// since the canvas only receives paste events while it has focus.
+func (l *loop) isPasteTarget(jsTarget js.Value) bool {
+	return jsTarget.Equal(l.htmlCanvas) ||
+		jsTarget.Equal(l.textInput.htmlInput) ||
+		jsTarget.Equal(l.htmlDocument.Get("body"))
+}
+
+// readFiles reads the contents of the specified JavaScript files
+// asynchronously and delivers them to the controller through the task
+// queue once all of them have been read.
+func (l *loop) readFiles(jsFiles js.Value, event FileEvent) {
+	count := jsFiles.Length()
+	if count == 0 {
+		return
+	}
+	files := make([]*File, count)
+	remaining := count
+
+	var callbacks []js.Func
+	complete := func() {
+		remaining--
+		if remaining > 0 {
+			return
+		}
+		for _, callback := range callbacks {
+			callback.Release()
+		}
+		for _, file := range files {
+			if file != nil {
+				event.Files = append(event.Files, *file)
+			}
+		}
+		l.Schedule(func() {
+			if controller, ok := l.controller.(FileController); ok {
+				controller.OnFileEvent(l, event)
+			}
+		})
+	}
+
+	for i := range count {
+		jsFile := jsFiles.Index(i)
+		name := jsFile.Get("name").String()
+		onLoad := js.FuncOf(func(this js.Value, args []js.Value) any {
+			jsData := js.Global().Get("Uint8Array").New(args[0])
+			data := make([]byte, jsData.Length())
+			js.CopyBytesToGo(data, jsData)
+			files[i] = &File{
+				Name: name,
+				Type: jsFile.Get("type").String(),
+				Data: data,
+			}
+			complete()
+			return nil
+		})
+		onError := js.FuncOf(func(this js.Value, args []js.Value) any {
+			logger.Warn("Failed to read file",
+				slog.String("name", name),
+				slog.String("error", jsErrorMessage(args)),
+			)
+			complete()
+			return nil
+		})
+		callbacks = append(callbacks, onLoad, onError)
+		readJSFile(jsFile, onLoad, onError)
+	}
+}
+
+// readJSFile reads the contents of a JavaScript file as an ArrayBuffer
+// and passes it to onLoad. Browsers that lack Blob.arrayBuffer fall back
+// to a FileReader.
+func readJSFile(jsFile js.Value, onLoad, onError js.Func) {
+	if !jsFile.Get("arrayBuffer").IsUndefined() {
+		jsFile.Call("arrayBuffer").Call("then", onLoad, onError)
+		return
+	}
+	jsReader := js.Global().Get("FileReader").New()
+	var onReaderLoad, onReaderError js.Func
+	release := func() {
+		onReaderLoad.Release()
+		onReaderError.Release()
+	}
+	onReaderLoad = js.FuncOf(func(this js.Value, args []js.Value) any {
+		release()
+		onLoad.Invoke(jsReader.Get("result"))
+		return nil
+	})
+	onReaderError = js.FuncOf(func(this js.Value, args []js.Value) any {
+		release()
+		onError.Invoke(jsReader.Get("error"))
+		return nil
+	})
+	jsReader.Set("onload", onReaderLoad)
+	jsReader.Set("onerror", onReaderError)
+	jsReader.Call("readAsArrayBuffer", jsFile)
+}
+
+// hasDataTransferFiles returns whether a drag operation carries files, as
+// opposed to text or links.
+func hasDataTransferFiles(jsDataTransfer js.Value) bool {
+	if jsDataTransfer.IsUndefined() || jsDataTransfer.IsNull() {
+		return false
+	}
+	jsTypes := jsDataTransfer.Get("types")
+	for i := range jsTypes.Length() {
+		if jsTypes.Index(i).String() == "Files" {
+			return true
+		}
+	}
+	return false
+}
+
+func jsErrorMessage(args []js.Value) string {
+	if len(args) == 0 || args[0].IsUndefined() || args[0].IsNull() {
+		return "unknown error"
+	}
+	if jsMessage := args[0].Get("message"); jsMessage.Type() == js.TypeString {
+		return jsMessage.String()
+	}
+	return fmt.Sprint(args[0])
+}
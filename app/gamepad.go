@@ -1,3 +1,5 @@
+//go:build js && wasm
+
 package app
 
 import (
@@ -10,14 +12,16 @@ import (
 
 // NOTE: Chrome does not follow the specification and the Gamepad object
 // reference cannot be stored and reused. It contains a snapshot of some
-// state which does not get updated. This makes using the connect and disconnect
-// event handlers pointless.
-//
-// Shame...
+// state which does not get updated. Instead, the loop fetches fresh
+// snapshots through navigator.getGamepads once per frame and assigns them
+// to the gamepad slots. The connect and disconnect events are only used as
+// hints that the set of gamepads has changed.
 
-func newGamepad(index int) *Gamepad {
+func newGamepad(os app.OS) *Gamepad {
 	return &Gamepad{
-		index: index,
+		os:           os,
+		browserIndex: -1,
+		snapshot:     js.Null(),
 
 		isDirty:     true,
 		isConnected: false,
@@ -29,7 +33,21 @@ func newGamepad(index int) *Gamepad {
 }
 
 type Gamepad struct {
-	index int
+	os app.OS
+
+	// id is the browser id of the gamepad that last occupied this slot.
+	// It is retained after a disconnect so that the same gamepad can be
+	// assigned to the same slot when it reconnects.
+	id string
+
+	// browserIndex is the index of the gamepad in the navigator.getGamepads
+	// array or -1 if the slot is not currently occupied.
+	browserIndex int
+
+	snapshot js.Value
+
+	hapticQueue    []HapticEffect
+	hapticDeadline time.Time
 
 	isDirty     bool
 	isConnected bool
@@ -189,32 +207,42 @@ func (g *Gamepad) BackButton() bool {
 }
 
 func (g *Gamepad) Pulse(intensity float64, duration time.Duration) {
-	jsGamepad := g.jsGamepad()
-	if jsGamepad.IsUndefined() || jsGamepad.IsNull() {
-		return
-	}
-	jsActuator := jsGamepad.Get("vibrationActuator")
-	if jsActuator.IsUndefined() || jsActuator.IsNull() {
-		return
-	}
-	jsActuator.Call("playEffect", "dual-rumble", map[string]any{
-		"startDelay":      0,
-		"duration":        duration.Milliseconds(),
-		"weakMagnitude":   intensity,
-		"strongMagnitude": intensity,
+	g.PlayHapticEffect(HapticEffect{
+		Type:            HapticEffectTypeDualRumble,
+		Duration:        duration,
+		StrongMagnitude: intensity,
+		WeakMagnitude:   intensity,
 	})
 }
 
-func (g *Gamepad) markDirty() {
+// ID returns the id that the browser reports for the gamepad that
+// occupies or last occupied this slot.
+func (g *Gamepad) ID() string {
+	return g.id
+}
+
+// assign updates the slot with the latest snapshot of the browser gamepad
+// at the specified browser index.
+func (g *Gamepad) assign(browserIndex int, jsGamepad js.Value) {
+	g.browserIndex = browserIndex
+	g.id = jsGamepad.Get("id").String()
+	g.snapshot = jsGamepad
 	g.isDirty = true
 }
 
-func (g *Gamepad) jsGamepad() js.Value {
-	jsGamepads := js.Global().Get("navigator").Call("getGamepads")
-	if jsGamepads.IsUndefined() || jsGamepads.IsNull() {
-		return js.Null()
+// unassign marks the slot as no longer occupied.
+func (g *Gamepad) unassign() {
+	if g.browserIndex < 0 {
+		return
 	}
-	return jsGamepads.Index(g.index)
+	g.browserIndex = -1
+	g.snapshot = js.Null()
+	g.hapticQueue = g.hapticQueue[:0]
+	g.isDirty = true
+}
+
+func (g *Gamepad) jsGamepad() js.Value {
+	return g.snapshot
 }
 
 func (g *Gamepad) refresh() {
@@ -224,34 +252,42 @@ func (g *Gamepad) refresh() {
 	jsGamepad := g.jsGamepad()
 	g.isDirty = false
 	g.isConnected = !jsGamepad.IsUndefined() && !jsGamepad.IsNull() && jsGamepad.Get("connected").Bool()
+	var mapping *gamepadMapping
 	if g.isConnected {
-		g.isSupported = jsGamepad.Get("mapping").String() == "standard"
+		mapping, g.isSupported = g.lookupMapping(jsGamepad)
 	} else {
 		g.isSupported = false
 	}
 	if g.isSupported {
-		axes := jsGamepad.Get("axes")
-		buttons := jsGamepad.Get("buttons")
-		g.leftStickX = axes.Index(0).Float()
-		g.leftStickY = axes.Index(1).Float()
-		g.leftStickButton = buttons.Index(10).Get("pressed").Bool()
-		g.rightStickX = axes.Index(2).Float()
-		g.rightStickY = axes.Index(3).Float()
-		g.rightStickButton = buttons.Index(11).Get("pressed").Bool()
-		g.leftBumperButton = buttons.Index(4).Get("pressed").Bool()
-		g.leftTrigger = buttons.Index(6).Get("value").Float()
-		g.rightBumperButton = buttons.Index(5).Get("pressed").Bool()
-		g.rightTrigger = buttons.Index(7).Get("value").Float()
-		g.dpadLeftButton = buttons.Index(14).Get("pressed").Bool()
-		g.dpadRightButton = buttons.Index(15).Get("pressed").Bool()
-		g.dpadUpButton = buttons.Index(12).Get("pressed").Bool()
-		g.dpadDownButton = buttons.Index(13).Get("pressed").Bool()
-		g.actionLeftButton = buttons.Index(2).Get("pressed").Bool()
-		g.actionRightButton = buttons.Index(1).Get("pressed").Bool()
-		g.actionUpButton = buttons.Index(3).Get("pressed").Bool()
-		g.actionDownButton = buttons.Index(0).Get("pressed").Bool()
-		g.forwardButton = buttons.Index(9).Get("pressed").Bool()
-		g.backButton = buttons.Index(8).Get("pressed").Bool()
+		axes := jsFloatArray(jsGamepad.Get("axes"), func(jsAxis js.Value) float64 {
+			return jsAxis.Float()
+		})
+		buttons := jsFloatArray(jsGamepad.Get("buttons"), func(jsButton js.Value) float64 {
+			if jsButton.Get("pressed").Bool() {
+				return max(jsButton.Get("value").Float(), 1.0)
+			}
+			return jsButton.Get("value").Float()
+		})
+		g.leftStickX = mapping.Axis(gamepadTargetLeftX, axes, buttons)
+		g.leftStickY = mapping.Axis(gamepadTargetLeftY, axes, buttons)
+		g.leftStickButton = mapping.Button(gamepadTargetLeftStick, axes, buttons)
+		g.rightStickX = mapping.Axis(gamepadTargetRightX, axes, buttons)
+		g.rightStickY = mapping.Axis(gamepadTargetRightY, axes, buttons)
+		g.rightStickButton = mapping.Button(gamepadTargetRightStick, axes, buttons)
+		g.leftBumperButton = mapping.Button(gamepadTargetLeftShoulder, axes, buttons)
+		g.leftTrigger = mapping.Trigger(gamepadTargetLeftTrigger, axes, buttons)
+		g.rightBumperButton = mapping.Button(gamepadTargetRightShoulder, axes, buttons)
+		g.rightTrigger = mapping.Trigger(gamepadTargetRightTrigger, axes, buttons)
+		g.dpadLeftButton = mapping.Button(gamepadTargetDpadLeft, axes, buttons)
+		g.dpadRightButton = mapping.Button(gamepadTargetDpadRight, axes, buttons)
+		g.dpadUpButton = mapping.Button(gamepadTargetDpadUp, axes, buttons)
+		g.dpadDownButton = mapping.Button(gamepadTargetDpadDown, axes, buttons)
+		g.actionLeftButton = mapping.Button(gamepadTargetX, axes, buttons)
+		g.actionRightButton = mapping.Button(gamepadTargetB, axes, buttons)
+		g.actionUpButton = mapping.Button(gamepadTargetY, axes, buttons)
+		g.actionDownButton = mapping.Button(gamepadTargetA, axes, buttons)
+		g.forwardButton = mapping.Button(gamepadTargetStart, axes, buttons)
+		g.backButton = mapping.Button(gamepadTargetBack, axes, buttons)
 	} else {
 		g.leftStickX = 0.0
 		g.leftStickY = 0.0
@@ -276,6 +312,25 @@ func (g *Gamepad) refresh() {
 	}
 }
 
+// lookupMapping determines how the raw axes and buttons of the gamepad
+// should be interpreted. Gamepads that the browser reports with the
+// "standard" mapping are used as is, while others require a mapping to
+// have been registered through AddGamepadMapping or SetGamepadMapping.
+func (g *Gamepad) lookupMapping(jsGamepad js.Value) (*gamepadMapping, bool) {
+	if jsGamepad.Get("mapping").String() == "standard" {
+		return standardGamepadMapping, true
+	}
+	return gamepadMappings.Lookup(jsGamepad.Get("id").String(), g.os)
+}
+
+func jsFloatArray(jsArray js.Value, fn func(js.Value) float64) []float64 {
+	result := make([]float64, jsArray.Length())
+	for i := range result {
+		result[i] = fn(jsArray.Index(i))
+	}
+	return result
+}
+
 func deadzoneValue(value, deadzone float64) float64 {
 	if math.Signbit(value) {
 		// negative
@@ -0,0 +1,151 @@
+//go:build js && wasm
+
+package app
+
+import (
+	"syscall/js"
+	"time"
+)
+
+// HapticEffectType specifies the kind of vibration that is produced by
+// a gamepad actuator.
+type HapticEffectType string
+
+const (
+	// HapticEffectTypeDualRumble uses the strong (low frequency) and weak
+	// (high frequency) rumble motors in the grips of the gamepad.
+	HapticEffectTypeDualRumble HapticEffectType = "dual-rumble"
+
+	// HapticEffectTypeTriggerRumble uses the motors in the triggers of the
+	// gamepad, in addition to the ones in the grips.
+	HapticEffectTypeTriggerRumble HapticEffectType = "trigger-rumble"
+)
+
+// HapticEffect describes a single vibration of a gamepad.
+type HapticEffect struct {
+
+	// Type specifies which motors are used.
+	Type HapticEffectType
+
+	// StartDelay is the amount of time to wait before the effect starts.
+	StartDelay time.Duration
+
+	// Duration is the amount of time the effect lasts.
+	Duration time.Duration
+
+	// StrongMagnitude is the intensity of the low frequency rumble motor
+	// in the range [0.0, 1.0].
+	StrongMagnitude float64
+
+	// WeakMagnitude is the intensity of the high frequency rumble motor
+	// in the range [0.0, 1.0].
+	WeakMagnitude float64
+
+	// LeftTrigger is the intensity of the left trigger motor in the range
+	// [0.0, 1.0]. It is only used by HapticEffectTypeTriggerRumble.
+	LeftTrigger float64
+
+	// RightTrigger is the intensity of the right trigger motor in the range
+	// [0.0, 1.0]. It is only used by HapticEffectTypeTriggerRumble.
+	RightTrigger float64
+}
+
+// TotalDuration returns the amount of time from the moment the effect is
+// played until it completes.
+func (e HapticEffect) TotalDuration() time.Duration {
+	return e.StartDelay + e.Duration
+}
+
+// SupportsHapticEffect returns whether the gamepad can play effects of
+// the specified type.
+func (g *Gamepad) SupportsHapticEffect(effectType HapticEffectType) bool {
+	jsActuator := g.jsActuator()
+	if jsActuator.IsNull() {
+		return false
+	}
+	jsEffects := jsActuator.Get("effects")
+	if jsEffects.IsUndefined() || jsEffects.IsNull() {
+		// Older browsers do not report the supported effects but are
+		// able to play dual-rumble ones.
+		return effectType == HapticEffectTypeDualRumble
+	}
+	for i := range jsEffects.Length() {
+		if jsEffects.Index(i).String() == string(effectType) {
+			return true
+		}
+	}
+	return false
+}
+
+// PlayHapticEffect starts the specified effect, replacing any effect or
+// sequence that is currently playing. The effect is ignored if the
+// gamepad does not support its type.
+func (g *Gamepad) PlayHapticEffect(effect HapticEffect) {
+	g.hapticQueue = g.hapticQueue[:0]
+	g.playHapticEffect(effect)
+}
+
+// PlayHapticSequence plays the specified effects one after the other,
+// replacing any effect or sequence that is currently playing. Each effect
+// starts once the previous one has completed, including its StartDelay.
+func (g *Gamepad) PlayHapticSequence(effects []HapticEffect) {
+	g.hapticQueue = append(g.hapticQueue[:0], effects...)
+	g.hapticDeadline = time.Time{}
+	g.updateHaptics(time.Now())
+}
+
+// ResetHaptics stops any effect or sequence that is currently playing.
+func (g *Gamepad) ResetHaptics() {
+	g.hapticQueue = g.hapticQueue[:0]
+	g.hapticDeadline = time.Time{}
+	jsActuator := g.jsActuator()
+	if jsActuator.IsNull() || jsActuator.Get("reset").IsUndefined() {
+		return
+	}
+	jsActuator.Call("reset")
+}
+
+// updateHaptics plays the next effect of a queued sequence, if the
+// previous one has completed.
+func (g *Gamepad) updateHaptics(now time.Time) {
+	if len(g.hapticQueue) == 0 || now.Before(g.hapticDeadline) {
+		return
+	}
+	effect := g.hapticQueue[0]
+	g.hapticQueue = g.hapticQueue[1:]
+	g.hapticDeadline = now.Add(effect.TotalDuration())
+	g.playHapticEffect(effect)
+}
+
+func (g *Gamepad) playHapticEffect(effect HapticEffect) {
+	if !g.SupportsHapticEffect(effect.Type) {
+		return
+	}
+	params := map[string]any{
+		"startDelay":      effect.StartDelay.Milliseconds(),
+		"duration":        effect.Duration.Milliseconds(),
+		"strongMagnitude": clampMagnitude(effect.StrongMagnitude),
+		"weakMagnitude":   clampMagnitude(effect.WeakMagnitude),
+	}
+	if effect.Type == HapticEffectTypeTriggerRumble {
+		params["leftTrigger"] = clampMagnitude(effect.LeftTrigger)
+		params["rightTrigger"] = clampMagnitude(effect.RightTrigger)
+	}
+	g.jsActuator().Call("playEffect", string(effect.Type), params)
+}
+
+func (g *Gamepad) jsActuator() js.Value {
+	jsGamepad := g.jsGamepad()
+	if jsGamepad.IsUndefined() || jsGamepad.IsNull() {
+		return js.Null()
+	}
+	jsActuator := jsGamepad.Get("vibrationActuator")
+	if jsActuator.IsUndefined() || jsActuator.IsNull() {
+		return js.Null()
+	}
+	return jsActuator
+}
+
+func clampMagnitude(value float64) float64 {
+	return min(max(value, 0.0), 1.0)
+}
@@ -0,0 +1,466 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mokiat/lacking/app"
+)
+
+// AddGamepadMapping registers a gamepad mapping that is specified in
+// the SDL gamecontrollerdb format
+// (e.g. "030000006d04000016c2000000000000,Logitech Dual Action,a:b1,...").
+//
+// The mapping is applied to gamepads that the browser does not report
+// with the "standard" mapping and whose vendor and product IDs match the
+// ones encoded in the mapping GUID. Mappings that specify a platform
+// are only used when running on that platform, since browsers expose
+// the raw axes and buttons in the order reported by the OS.
+//
+// NOTE: Browsers do not expose hat switches, so hat bindings (e.g.
+// "dpup:h0.1") are ignored.
+func AddGamepadMapping(mapping string) error {
+	parsed, err := parseGamepadMapping(mapping)
+	if err != nil {
+		return err
+	}
+	vendor, product, ok := parseSDLGUID(parsed.guid)
+	if !ok {
+		return fmt.Errorf("mapping %q has an unsupported GUID %q", parsed.name, parsed.guid)
+	}
+	gamepadMappings.AddDevice(gamepadDevice{
+		vendor:  vendor,
+		product: product,
+	}, parsed)
+	return nil
+}
+
+// AddGamepadMappings registers all gamepad mappings that are contained
+// in the specified gamecontrollerdb.txt content. Empty lines and
+// comments are skipped. The first invalid mapping aborts the process.
+func AddGamepadMappings(db string) error {
+	scanner := bufio.NewScanner(strings.NewReader(db))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := AddGamepadMapping(line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// SetGamepadMapping registers a gamepad mapping for the gamepad that the
+// browser reports with the specified id. The mapping uses the SDL
+// gamecontrollerdb format, though the GUID and platform fields are
+// ignored. Such mappings take precedence over ones registered through
+// AddGamepadMapping.
+func SetGamepadMapping(id, mapping string) error {
+	parsed, err := parseGamepadMapping(mapping)
+	if err != nil {
+		return err
+	}
+	parsed.platform = ""
+	gamepadMappings.AddID(id, parsed)
+	return nil
+}
+
+var gamepadMappings = newGamepadMappingDatabase()
+
+func newGamepadMappingDatabase() *gamepadMappingDatabase {
+	return &gamepadMappingDatabase{
+		byID:     make(map[string]*gamepadMapping),
+		byDevice: make(map[gamepadDevice][]*gamepadMapping),
+	}
+}
+
+type gamepadMappingDatabase struct {
+	mu       sync.RWMutex
+	byID     map[string]*gamepadMapping
+	byDevice map[gamepadDevice][]*gamepadMapping
+}
+
+func (d *gamepadMappingDatabase) AddID(id string, mapping *gamepadMapping) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.byID[id] = mapping
+}
+
+func (d *gamepadMappingDatabase) AddDevice(device gamepadDevice, mapping *gamepadMapping) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	// Newer mappings replace older ones for the same platform, which
+	// allows applications to override entries from a bundled database.
+	mappings := d.byDevice[device]
+	for i, candidate := range mappings {
+		if candidate.platform == mapping.platform {
+			mappings[i] = mapping
+			return
+		}
+	}
+	d.byDevice[device] = append(mappings, mapping)
+}
+
+// Lookup returns the mapping that should be used for the gamepad with the
+// specified browser id when running on the specified OS.
+func (d *gamepadMappingDatabase) Lookup(id string, os app.OS) (*gamepadMapping, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if mapping, ok := d.byID[id]; ok {
+		return mapping, true
+	}
+	device, ok := parseGamepadDevice(id)
+	if !ok {
+		return nil, false
+	}
+	var fallback *gamepadMapping
+	for _, mapping := range d.byDevice[device] {
+		switch mapping.platform {
+		case sdlPlatformName(os):
+			return mapping, true
+		case "", "Web":
+			fallback = mapping
+		}
+	}
+	return fallback, fallback != nil
+}
+
+type gamepadDevice struct {
+	vendor  uint16
+	product uint16
+}
+
+var (
+	// chromeGamepadIDPattern matches ids like
+	// "Wireless Controller (STANDARD GAMEPAD Vendor: 054c Product: 09cc)".
+	chromeGamepadIDPattern = regexp.MustCompile(`Vendor: ([0-9a-fA-F]{1,4}) Product: ([0-9a-fA-F]{1,4})`)
+
+	// firefoxGamepadIDPattern matches ids like
+	// "054c-09cc-Wireless Controller".
+	firefoxGamepadIDPattern = regexp.MustCompile(`^([0-9a-fA-F]{1,4})-([0-9a-fA-F]{1,4})-`)
+)
+
+func parseGamepadDevice(id string) (gamepadDevice, bool) {
+	match := chromeGamepadIDPattern.FindStringSubmatch(id)
+	if match == nil {
+		match = firefoxGamepadIDPattern.FindStringSubmatch(id)
+	}
+	if match == nil {
+		return gamepadDevice{}, false
+	}
+	vendor, err := strconv.ParseUint(match[1], 16, 16)
+	if err != nil {
+		return gamepadDevice{}, false
+	}
+	product, err := strconv.ParseUint(match[2], 16, 16)
+	if err != nil {
+		return gamepadDevice{}, false
+	}
+	return gamepadDevice{
+		vendor:  uint16(vendor),
+		product: uint16(product),
+	}, true
+}
+
+// parseSDLGUID extracts the USB vendor and product IDs from an SDL
+// joystick GUID. The IDs are stored as little-endian 16bit values at
+// byte offsets 4 and 8.
+func parseSDLGUID(guid string) (vendor, product uint16, ok bool) {
+	if len(guid) != 32 {
+		return 0, 0, false
+	}
+	readUint16 := func(offset int) (uint16, bool) {
+		low, err := strconv.ParseUint(guid[offset*2:offset*2+2], 16, 8)
+		if err != nil {
+			return 0, false
+		}
+		high, err := strconv.ParseUint(guid[offset*2+2:offset*2+4], 16, 8)
+		if err != nil {
+			return 0, false
+		}
+		return uint16(high<<8 | low), true
+	}
+	vendor, vendorOK := readUint16(4)
+	product, productOK := readUint16(8)
+	return vendor, product, vendorOK && productOK
+}
+
+func sdlPlatformName(os app.OS) string {
+	switch os {
+	case app.OSLinux:
+		return "Linux"
+	case app.OSWindows:
+		return "Windows"
+	case app.OSDarwin:
+		return "Mac OS X"
+	default:
+		return ""
+	}
+}
+
+type gamepadTarget int
+
+const (
+	gamepadTargetA gamepadTarget = iota
+	gamepadTargetB
+	gamepadTargetX
+	gamepadTargetY
+	gamepadTargetBack
+	gamepadTargetStart
+	gamepadTargetLeftStick
+	gamepadTargetRightStick
+	gamepadTargetLeftShoulder
+	gamepadTargetRightShoulder
+	gamepadTargetDpadUp
+	gamepadTargetDpadDown
+	gamepadTargetDpadLeft
+	gamepadTargetDpadRight
+	gamepadTargetLeftX
+	gamepadTargetLeftY
+	gamepadTargetRightX
+	gamepadTargetRightY
+	gamepadTargetLeftTrigger
+	gamepadTargetRightTrigger
+	gamepadTargetCount
+)
+
+var gamepadTargetNames = map[string]gamepadTarget{
+	"a":             gamepadTargetA,
+	"b":             gamepadTargetB,
+	"x":             gamepadTargetX,
+	"y":             gamepadTargetY,
+	"back":          gamepadTargetBack,
+	"start":         gamepadTargetStart,
+	"leftstick":     gamepadTargetLeftStick,
+	"rightstick":    gamepadTargetRightStick,
+	"leftshoulder":  gamepadTargetLeftShoulder,
+	"rightshoulder": gamepadTargetRightShoulder,
+	"dpup":          gamepadTargetDpadUp,
+	"dpdown":        gamepadTargetDpadDown,
+	"dpleft":        gamepadTargetDpadLeft,
+	"dpright":       gamepadTargetDpadRight,
+	"leftx":         gamepadTargetLeftX,
+	"lefty":         gamepadTargetLeftY,
+	"rightx":        gamepadTargetRightX,
+	"righty":        gamepadTargetRightY,
+	"lefttrigger":   gamepadTargetLeftTrigger,
+	"righttrigger":  gamepadTargetRightTrigger,
+}
+
+type gamepadInputKind int
+
+const (
+	gamepadInputKindButton gamepadInputKind = iota
+	gamepadInputKindAxis
+)
+
+// gamepadBinding connects a raw browser button or axis to a target
+// control.
+type gamepadBinding struct {
+	kind   gamepadInputKind
+	index  int
+	invert bool
+
+	// inputHalf restricts an axis input to its positive (+1) or negative
+	// (-1) half. A value of 0 means that the full axis is used.
+	inputHalf int
+
+	// outputHalf restricts the binding to the positive (+1) or negative
+	// (-1) half of a target axis. A value of 0 means the full axis.
+	outputHalf int
+}
+
+// read returns the magnitude of the bound input. Full axes are reported
+// in the range [-1.0, 1.0] and everything else in the range [0.0, 1.0].
+func (b gamepadBinding) read(axes, buttons []float64) float64 {
+	switch b.kind {
+	case gamepadInputKindButton:
+		if b.index >= len(buttons) {
+			return 0.0
+		}
+		return buttons[b.index]
+	case gamepadInputKindAxis:
+		if b.index >= len(axes) {
+			return 0.0
+		}
+		value := axes[b.index]
+		if b.invert {
+			value = -value
+		}
+		switch b.inputHalf {
+		case 1:
+			return max(value, 0.0)
+		case -1:
+			return max(-value, 0.0)
+		default:
+			return value
+		}
+	default:
+		return 0.0
+	}
+}
+
+func (b gamepadBinding) isFullAxis() bool {
+	return b.kind == gamepadInputKindAxis && b.inputHalf == 0
+}
+
+type gamepadMapping struct {
+	guid     string
+	name     string
+	platform string
+	bindings [gamepadTargetCount][]gamepadBinding
+}
+
+// Axis evaluates a stick axis target, producing a value in the range
+// [-1.0, 1.0].
+func (m *gamepadMapping) Axis(target gamepadTarget, axes, buttons []float64) float64 {
+	var result float64
+	for _, binding := range m.bindings[target] {
+		value := binding.read(axes, buttons)
+		switch binding.outputHalf {
+		case 1:
+			result += abs(value)
+		case -1:
+			result -= abs(value)
+		default:
+			result += value
+		}
+	}
+	return min(max(result, -1.0), 1.0)
+}
+
+// Trigger evaluates a trigger target, producing a value in the range
+// [0.0, 1.0].
+func (m *gamepadMapping) Trigger(target gamepadTarget, axes, buttons []float64) float64 {
+	var result float64
+	for _, binding := range m.bindings[target] {
+		value := binding.read(axes, buttons)
+		if binding.isFullAxis() {
+			// Full axis triggers rest at -1.0 and are fully pressed at 1.0.
+			value = (value + 1.0) / 2.0
+		}
+		result = max(result, value)
+	}
+	return min(max(result, 0.0), 1.0)
+}
+
+// Button evaluates a button target.
+func (m *gamepadMapping) Button(target gamepadTarget, axes, buttons []float64) bool {
+	for _, binding := range m.bindings[target] {
+		if binding.read(axes, buttons) > 0.5 {
+			return true
+		}
+	}
+	return false
+}
+
+// standardGamepadMapping describes the W3C "standard" gamepad layout.
+var standardGamepadMapping = mustParseGamepadMapping(
+	"00000000000000000000000000000000,Standard Gamepad," +
+		"a:b0,b:b1,x:b2,y:b3,leftshoulder:b4,rightshoulder:b5," +
+		"lefttrigger:b6,righttrigger:b7,back:b8,start:b9," +
+		"leftstick:b10,rightstick:b11,dpup:b12,dpdown:b13,dpleft:b14,dpright:b15," +
+		"leftx:a0,lefty:a1,rightx:a2,righty:a3,",
+)
+
+func mustParseGamepadMapping(mapping string) *gamepadMapping {
+	result, err := parseGamepadMapping(mapping)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+func parseGamepadMapping(mapping string) (*gamepadMapping, error) {
+	fields := strings.Split(strings.TrimSpace(mapping), ",")
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("mapping %q is missing GUID or name", mapping)
+	}
+	result := &gamepadMapping{
+		guid: strings.ToLower(fields[0]),
+		name: fields[1],
+	}
+	for _, field := range fields[2:] {
+		if field == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(field, ":")
+		if !ok {
+			return nil, fmt.Errorf("mapping %q has malformed element %q", result.name, field)
+		}
+		if key == "platform" {
+			result.platform = value
+			continue
+		}
+
+		var outputHalf int
+		switch {
+		case strings.HasPrefix(key, "+"):
+			outputHalf = 1
+			key = key[1:]
+		case strings.HasPrefix(key, "-"):
+			outputHalf = -1
+			key = key[1:]
+		}
+		target, ok := gamepadTargetNames[key]
+		if !ok {
+			continue // unsupported target (e.g. guide, paddle1, touchpad)
+		}
+		if strings.HasPrefix(value, "h") {
+			continue // hats are not exposed by browsers
+		}
+		binding, err := parseGamepadBinding(value)
+		if err != nil {
+			return nil, fmt.Errorf("mapping %q has invalid element %q: %w", result.name, field, err)
+		}
+		binding.outputHalf = outputHalf
+		result.bindings[target] = append(result.bindings[target], binding)
+	}
+	return result, nil
+}
+
+func parseGamepadBinding(value string) (gamepadBinding, error) {
+	var binding gamepadBinding
+	switch {
+	case strings.HasPrefix(value, "+"):
+		binding.inputHalf = 1
+		value = value[1:]
+	case strings.HasPrefix(value, "-"):
+		binding.inputHalf = -1
+		value = value[1:]
+	}
+	if strings.HasSuffix(value, "~") {
+		binding.invert = true
+		value = value[:len(value)-1]
+	}
+	if value == "" {
+		return gamepadBinding{}, fmt.Errorf("missing input")
+	}
+	switch value[0] {
+	case 'b':
+		binding.kind = gamepadInputKindButton
+	case 'a':
+		binding.kind = gamepadInputKindAxis
+	default:
+		return gamepadBinding{}, fmt.Errorf("unknown input kind %q", value[0])
+	}
+	index, err := strconv.Atoi(value[1:])
+	if err != nil || index < 0 {
+		return gamepadBinding{}, fmt.Errorf("invalid input index %q", value[1:])
+	}
+	binding.index = index
+	return binding, nil
+}
+
+func abs(value float64) float64 {
+	if value < 0.0 {
+		return -value
+	}
+	return value
+}
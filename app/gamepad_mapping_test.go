@@ -0,0 +1,253 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/mokiat/lacking/app"
+)
+
+const dualActionMapping = "030000006d04000016c2000000000000,Logitech Dual Action," +
+	"a:b1,b:b2,x:b0,y:b3,back:b8,start:b9,guide:b12,dpup:h0.1," +
+	"leftshoulder:b4,rightshoulder:b5,lefttrigger:b6,righttrigger:a5," +
+	"leftx:a0,lefty:a1~,+rightx:+a2,-rightx:b10,righty:-a3,platform:Linux,"
+
+func TestParseGamepadMapping(t *testing.T) {
+	mapping, err := parseGamepadMapping(dualActionMapping)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mapping.guid != "030000006d04000016c2000000000000" {
+		t.Errorf("unexpected guid %q", mapping.guid)
+	}
+	if mapping.name != "Logitech Dual Action" {
+		t.Errorf("unexpected name %q", mapping.name)
+	}
+	if mapping.platform != "Linux" {
+		t.Errorf("unexpected platform %q", mapping.platform)
+	}
+	if bindings := mapping.bindings[gamepadTargetDpadUp]; len(bindings) != 0 {
+		t.Errorf("expected hat bindings to be ignored, got %v", bindings)
+	}
+
+	testCases := []struct {
+		target   gamepadTarget
+		expected []gamepadBinding
+	}{
+		{
+			target:   gamepadTargetA,
+			expected: []gamepadBinding{{kind: gamepadInputKindButton, index: 1}},
+		},
+		{
+			target:   gamepadTargetLeftY,
+			expected: []gamepadBinding{{kind: gamepadInputKindAxis, index: 1, invert: true}},
+		},
+		{
+			target:   gamepadTargetRightTrigger,
+			expected: []gamepadBinding{{kind: gamepadInputKindAxis, index: 5}},
+		},
+		{
+			target: gamepadTargetRightX,
+			expected: []gamepadBinding{
+				{kind: gamepadInputKindAxis, index: 2, inputHalf: 1, outputHalf: 1},
+				{kind: gamepadInputKindButton, index: 10, outputHalf: -1},
+			},
+		},
+		{
+			target:   gamepadTargetRightY,
+			expected: []gamepadBinding{{kind: gamepadInputKindAxis, index: 3, inputHalf: -1}},
+		},
+	}
+	for _, tc := range testCases {
+		bindings := mapping.bindings[tc.target]
+		if len(bindings) != len(tc.expected) {
+			t.Errorf("target %d: expected %v, got %v", tc.target, tc.expected, bindings)
+			continue
+		}
+		for i := range bindings {
+			if bindings[i] != tc.expected[i] {
+				t.Errorf("target %d: expected %v, got %v", tc.target, tc.expected, bindings)
+				break
+			}
+		}
+	}
+}
+
+func TestParseGamepadMappingMalformed(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mapping string
+	}{
+		{name: "empty", mapping: ""},
+		{name: "missing name", mapping: "030000006d04000016c2000000000000"},
+		{name: "element without separator", mapping: "guid,Name,a"},
+		{name: "missing input", mapping: "guid,Name,a:"},
+		{name: "unknown input kind", mapping: "guid,Name,a:c1"},
+		{name: "invalid button index", mapping: "guid,Name,a:bx"},
+		{name: "negative axis index", mapping: "guid,Name,leftx:a-1"},
+		{name: "missing input index", mapping: "guid,Name,leftx:+a~"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := parseGamepadMapping(tc.mapping); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func TestParseGamepadMappingUnsupportedTargets(t *testing.T) {
+	mapping, err := parseGamepadMapping("guid,Name,guide:b12,paddle1:b13,touchpad:b14,a:b0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mapping.bindings[gamepadTargetA]) != 1 {
+		t.Errorf("expected supported targets to be kept")
+	}
+}
+
+func TestParseSDLGUID(t *testing.T) {
+	testCases := []struct {
+		guid    string
+		vendor  uint16
+		product uint16
+		ok      bool
+	}{
+		{guid: "030000006d04000016c2000000000000", vendor: 0x046d, product: 0xc216, ok: true},
+		{guid: "050000004c050000cc09000000810000", vendor: 0x054c, product: 0x09cc, ok: true},
+		{guid: "030000006d04", ok: false},
+		{guid: "03000000zz04000016c2000000000000", ok: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.guid, func(t *testing.T) {
+			vendor, product, ok := parseSDLGUID(tc.guid)
+			if ok != tc.ok {
+				t.Fatalf("expected ok %t, got %t", tc.ok, ok)
+			}
+			if ok && (vendor != tc.vendor || product != tc.product) {
+				t.Errorf("expected %04x:%04x, got %04x:%04x", tc.vendor, tc.product, vendor, product)
+			}
+		})
+	}
+}
+
+func TestParseGamepadDevice(t *testing.T) {
+	testCases := []struct {
+		id       string
+		expected gamepadDevice
+		ok       bool
+	}{
+		{
+			id:       "Logitech Dual Action (Vendor: 046d Product: c216)",
+			expected: gamepadDevice{vendor: 0x046d, product: 0xc216},
+			ok:       true,
+		},
+		{
+			id:       "Wireless Controller (STANDARD GAMEPAD Vendor: 054c Product: 09cc)",
+			expected: gamepadDevice{vendor: 0x054c, product: 0x09cc},
+			ok:       true,
+		},
+		{
+			id:       "46d-c216-Logitech Dual Action",
+			expected: gamepadDevice{vendor: 0x046d, product: 0xc216},
+			ok:       true,
+		},
+		{
+			id: "Unknown Gamepad",
+			ok: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.id, func(t *testing.T) {
+			device, ok := parseGamepadDevice(tc.id)
+			if ok != tc.ok {
+				t.Fatalf("expected ok %t, got %t", tc.ok, ok)
+			}
+			if device != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, device)
+			}
+		})
+	}
+}
+
+func TestGamepadMappingDatabaseLookup(t *testing.T) {
+	db := newGamepadMappingDatabase()
+	device := gamepadDevice{vendor: 0x046d, product: 0xc216}
+	generic := mustParseGamepadMapping("030000006d04000016c2000000000000,Generic,a:b0")
+	linux := mustParseGamepadMapping("030000006d04000016c2000000000000,Linux,a:b1,platform:Linux")
+	windows := mustParseGamepadMapping("030000006d04000016c2000000000000,Windows,a:b2,platform:Windows")
+	custom := mustParseGamepadMapping("00000000000000000000000000000000,Custom,a:b3")
+	db.AddDevice(device, generic)
+	db.AddDevice(device, linux)
+	db.AddDevice(device, windows)
+	db.AddID("Custom Stick", custom)
+
+	chromeID := "Logitech Dual Action (Vendor: 046d Product: c216)"
+	testCases := []struct {
+		name     string
+		id       string
+		os       app.OS
+		expected *gamepadMapping
+	}{
+		{name: "platform specific", id: chromeID, os: app.OSLinux, expected: linux},
+		{name: "other platform", id: chromeID, os: app.OSWindows, expected: windows},
+		{name: "platform fallback", id: chromeID, os: app.OSDarwin, expected: generic},
+		{name: "by id", id: "Custom Stick", os: app.OSLinux, expected: custom},
+		{name: "unknown device", id: "Other (Vendor: 1234 Product: 5678)", os: app.OSLinux, expected: nil},
+		{name: "unparsable id", id: "Something", os: app.OSLinux, expected: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mapping, ok := db.Lookup(tc.id, tc.os)
+			if ok != (tc.expected != nil) {
+				t.Fatalf("expected found %t, got %t", tc.expected != nil, ok)
+			}
+			if mapping != tc.expected {
+				t.Errorf("expected mapping %q, got %q", tc.expected.name, mapping.name)
+			}
+		})
+	}
+}
+
+func TestGamepadMappingDatabaseReplace(t *testing.T) {
+	db := newGamepadMappingDatabase()
+	device := gamepadDevice{vendor: 1, product: 2}
+	first := mustParseGamepadMapping("guid,First,a:b0,platform:Linux")
+	second := mustParseGamepadMapping("guid,Second,a:b1,platform:Linux")
+	db.AddDevice(device, first)
+	db.AddDevice(device, second)
+
+	mapping, ok := db.Lookup("0001-0002-Pad", app.OSLinux)
+	if !ok || mapping != second {
+		t.Errorf("expected the newer mapping to replace the older one")
+	}
+}
+
+func TestGamepadMappingEvaluation(t *testing.T) {
+	mapping := mustParseGamepadMapping(dualActionMapping)
+	axes := []float64{0.5, 0.25, 0.75, -0.5, 0.0, 0.0}
+	buttons := make([]float64, 13)
+	buttons[1] = 1.0
+
+	if !mapping.Button(gamepadTargetA, axes, buttons) {
+		t.Errorf("expected A to be pressed")
+	}
+	if mapping.Button(gamepadTargetB, axes, buttons) {
+		t.Errorf("expected B to be released")
+	}
+	if value := mapping.Axis(gamepadTargetLeftY, axes, buttons); value != -0.25 {
+		t.Errorf("expected inverted left Y -0.25, got %f", value)
+	}
+	if value := mapping.Axis(gamepadTargetRightX, axes, buttons); value != 0.75 {
+		t.Errorf("expected right X 0.75, got %f", value)
+	}
+	buttons[10] = 1.0
+	if value := mapping.Axis(gamepadTargetRightX, axes, buttons); value != -0.25 {
+		t.Errorf("expected combined right X -0.25, got %f", value)
+	}
+	if value := mapping.Trigger(gamepadTargetRightTrigger, axes, buttons); value != 0.5 {
+		t.Errorf("expected resting full axis trigger at 0.5, got %f", value)
+	}
+	if value := mapping.Axis(gamepadTargetLeftX, nil, nil); value != 0.0 {
+		t.Errorf("expected missing axes to read as 0.0, got %f", value)
+	}
+}
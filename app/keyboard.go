@@ -2,7 +2,11 @@
 
 package app
 
-import "github.com/mokiat/lacking/app"
+import (
+	"syscall/js"
+
+	"github.com/mokiat/lacking/app"
+)
 
 var (
 	keyboardCodeMapping map[string]app.KeyCode
@@ -94,3 +98,52 @@ func init() {
 	keyboardCodeMapping["F11"] = app.KeyCodeF11
 	keyboardCodeMapping["F12"] = app.KeyCodeF12
 }
+
+// isCompositionKeyEvent returns whether the keyboard event is part of
+// an IME composition.
+func isCompositionKeyEvent(event js.Value) bool {
+	// NOTE: Some browsers report the key that starts a composition with
+	// key code 229 without setting isComposing.
+	return event.Get("isComposing").Bool() || event.Get("keyCode").Int() == 229
+}
+
+// typedCharacter returns the character that the keyboard event would
+// type, if any. Named keys (e.g. "Enter"), dead keys and shortcuts do not
+// produce characters.
+func typedCharacter(event js.Value) (rune, bool) {
+	key := []rune(event.Get("key").String())
+	if len(key) != 1 {
+		return 0, false
+	}
+	modifiers := modifiersFromEvent(event)
+	isShortcut := modifiers.Has(KeyModifierControl) || modifiers.Has(KeyModifierMeta)
+	if isShortcut && !modifiers.Has(KeyModifierAltGraph) {
+		return 0, false
+	}
+	return key[0], true
+}
+
+// modifiersFromEvent returns the modifier state of a keyboard or pointer
+// event.
+func modifiersFromEvent(event js.Value) KeyModifiers {
+	var modifiers KeyModifiers
+	if event.Get("shiftKey").Bool() {
+		modifiers |= KeyModifierShift
+	}
+	if event.Get("ctrlKey").Bool() {
+		modifiers |= KeyModifierControl
+	}
+	if event.Get("altKey").Bool() {
+		modifiers |= KeyModifierAlt
+	}
+	if event.Get("metaKey").Bool() {
+		modifiers |= KeyModifierMeta
+	}
+	if event.Call("getModifierState", "CapsLock").Bool() {
+		modifiers |= KeyModifierCapsLock
+	}
+	if event.Call("getModifierState", "AltGraph").Bool() {
+		modifiers |= KeyModifierAltGraph
+	}
+	return modifiers
+}
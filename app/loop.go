@@ -4,6 +4,7 @@ package app
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"syscall/js"
 	"time"
@@ -18,31 +19,60 @@ import (
 )
 
 const (
-	taskQueueSize         = 1024
-	taskProcessingTimeout = 30 * time.Millisecond
+	// minGamepadSlots is the number of gamepad slots that always exist, so
+	// that Gamepads can be served.
+	minGamepadSlots = 4
+
+	// gamepadDiscoveryInterval controls how often navigator.getGamepads
+	// is checked for new gamepads while none are connected. Browsers do not
+	// reliably dispatch gamepadconnected for gamepads that were attached
+	// before the page was loaded.
+	gamepadDiscoveryInterval = time.Second
 )
 
-func newLoop(htmlDocument, htmlCanvas js.Value, controller app.Controller) *loop {
-	return &loop{
-		platform:     newPlatform(),
-		htmlDocument: htmlDocument,
-		htmlCanvas:   htmlCanvas,
-		controller:   controller,
-		renderAPI:    jsrender.NewAPI(),
-		tasks:        make(chan func(), taskQueueSize),
-		gamepads: [4]*Gamepad{
-			newGamepad(0),
-			newGamepad(1),
-			newGamepad(2),
-			newGamepad(3),
+func newLoop(cfg *Config, htmlDocument, htmlCanvas js.Value, renderAPI render.API, controller app.Controller) *loop {
+	platform := newPlatform()
+	l := &loop{
+		platform:             platform,
+		htmlDocument:         htmlDocument,
+		htmlCanvas:           htmlCanvas,
+		controller:           controller,
+		renderAPI:            renderAPI,
+		unadjustedLockMotion: cfg.rawCursorMotion,
+		resizer:              newCanvasResizer(htmlCanvas, cfg.maxPixelRatio),
+		autoResize:           cfg.autoResize || cfg.fullscreen,
+		fullscreenPending:    cfg.fullscreen,
+		keyboardLock:         cfg.keyboardLock,
+		glExtensions:         cfg.glExtensions,
+		updateInterval:       cfg.updateInterval,
+		renderOnDemand:       cfg.renderOnDemand,
+		invalidated:          true,
+		maxFrameRate:         cfg.maxFrameRate,
+		maxUpdateSteps:       cfg.maxUpdateSteps,
+		tasks:                newTaskQueue(cfg.taskQueueLimit),
+		gamepads: []*Gamepad{
+			newGamepad(platform.OS()),
+			newGamepad(platform.OS()),
+			newGamepad(platform.OS()),
+			newGamepad(platform.OS()),
 		},
-		gamepadStates:     [4]gamepadState{},
+		pointers:          newPointerTracker(),
+		textInput:         newTextInput(htmlDocument, htmlCanvas),
+		passthrough:       cfg.passthrough,
+		storageName:       cfg.storageName,
+		pressedKeys:       make(map[app.KeyCode]struct{}),
+		visible:           htmlDocument.Get("visibilityState").String() != "hidden",
+		focused:           htmlDocument.Call("hasFocus").Bool(),
+		gamepadStates:     make([]gamepadState, minGamepadSlots),
+		gamepadsChanged:   true,
 		lastGamepadUpdate: time.Now(),
 		shouldStop:        false,
 	}
+	l.fetcher = newFetcher(l.Schedule, cfg.maxFetches, cfg.fetchCacheName)
+	return l
 }
 
-var _ app.Window = (*loop)(nil)
+var _ Window = (*loop)(nil)
 
 type loop struct {
 	platform          *platform
@@ -53,18 +83,65 @@ type loop struct {
 	audioAPI          audio.API
 	cursor            *Cursor
 	cursorLocked      bool
-	tasks             chan func()
-	gamepads          [4]*Gamepad
-	gamepadStates     [4]gamepadState
+	pointers          *pointerTracker
+	textInput         *textInput
+	modifiers         KeyModifiers
+	passthrough       KeyboardPassthroughFunc
+	storageName       string
+	storage           Storage
+	fetcher           *Fetcher
+	pressedKeys       map[app.KeyCode]struct{}
+	visible           bool
+	focused           bool
+	tasks             *taskQueue
+	gamepads          []*Gamepad
+	gamepadStates     []gamepadState
+	gamepadsChanged   bool
+	connectedGamepads int
+	lastGamepadPoll   time.Time
 	lastGamepadUpdate time.Time
 	shouldStop        bool
 
+	cursorLock           pointerLockState
+	unadjustedLockMotion bool
+	lockedMouseX         float64
+	lockedMouseY         float64
+	lastMouseX           float64
+	lastMouseY           float64
+	inUserGesture        bool
+
+	resizer           *canvasResizer
+	autoResize        bool
+	fullscreen        bool
+	fullscreenPending bool
+	keyboardLock      bool
+	windowedWidth     int
+	windowedHeight    int
+
+	glExtensions []string
+	contextLost  bool
+
+	renderOnDemand     bool
+	invalidated        bool
+	maxFrameRate       int
+	lastFrameTimestamp float64
+	frameInterval      time.Duration
+	renderDuration     time.Duration
+
+	updateInterval     time.Duration
+	maxUpdateSteps     int
+	updateAccumulator  time.Duration
+	lastUpdateTime     time.Time
+	interpolationAlpha float64
+
 	knownFramebufferWidth  int
 	knownFramebufferHeight int
 	knownWidth             int
 	knownHeight            int
 
-	clipboardCallback js.Func
+	clipboardCallback         js.Func
+	pointerLockRejectCallback js.Func
+	fullscreenRejectCallback  js.Func
 }
 
 func (l *loop) Run(audioEnabled bool) error {
@@ -79,58 +156,174 @@ func (l *loop) Run(audioEnabled bool) error {
 	l.controller.OnCreate(l)
 	defer l.controller.OnDestroy(l)
 
-	keydownCallback := js.FuncOf(l.onJSKeyDown)
+	keydownCallback := js.FuncOf(l.invalidating(l.onJSKeyDown))
 	defer keydownCallback.Release()
 	l.htmlCanvas.Call("addEventListener", "keydown", keydownCallback)
 	defer l.htmlCanvas.Call("removeEventListener", "keydown", keydownCallback)
 
-	keyupCallback := js.FuncOf(l.onJSKeyUp)
+	keyupCallback := js.FuncOf(l.invalidating(l.onJSKeyUp))
 	defer keyupCallback.Release()
 	l.htmlCanvas.Call("addEventListener", "keyup", keyupCallback)
 	defer l.htmlCanvas.Call("removeEventListener", "keyup", keyupCallback)
 
-	mouseEnterCallback := js.FuncOf(l.onJSMouseEnter)
+	l.textInput.Attach()
+	defer l.textInput.Detach()
+	htmlTextInput := l.textInput.htmlInput
+	htmlTextInput.Call("addEventListener", "keydown", keydownCallback)
+	defer htmlTextInput.Call("removeEventListener", "keydown", keydownCallback)
+	htmlTextInput.Call("addEventListener", "keyup", keyupCallback)
+	defer htmlTextInput.Call("removeEventListener", "keyup", keyupCallback)
+
+	textInputCallback := js.FuncOf(l.invalidating(l.onJSTextInput))
+	defer textInputCallback.Release()
+	htmlTextInput.Call("addEventListener", "input", textInputCallback)
+	defer htmlTextInput.Call("removeEventListener", "input", textInputCallback)
+
+	compositionStartCallback := js.FuncOf(l.invalidating(l.onJSCompositionStart))
+	defer compositionStartCallback.Release()
+	htmlTextInput.Call("addEventListener", "compositionstart", compositionStartCallback)
+	defer htmlTextInput.Call("removeEventListener", "compositionstart", compositionStartCallback)
+
+	compositionUpdateCallback := js.FuncOf(l.invalidating(l.onJSCompositionUpdate))
+	defer compositionUpdateCallback.Release()
+	htmlTextInput.Call("addEventListener", "compositionupdate", compositionUpdateCallback)
+	defer htmlTextInput.Call("removeEventListener", "compositionupdate", compositionUpdateCallback)
+
+	compositionEndCallback := js.FuncOf(l.invalidating(l.onJSCompositionEnd))
+	defer compositionEndCallback.Release()
+	htmlTextInput.Call("addEventListener", "compositionend", compositionEndCallback)
+	defer htmlTextInput.Call("removeEventListener", "compositionend", compositionEndCallback)
+
+	mouseEnterCallback := js.FuncOf(l.invalidating(l.onJSMouseEnter))
 	defer mouseEnterCallback.Release()
 	l.htmlCanvas.Call("addEventListener", "pointerenter", mouseEnterCallback)
 	defer l.htmlCanvas.Call("removeEventListener", "pointerenter", mouseEnterCallback)
 
-	mouseLeaveCallback := js.FuncOf(l.onJSMouseLeave)
+	mouseLeaveCallback := js.FuncOf(l.invalidating(l.onJSMouseLeave))
 	defer mouseLeaveCallback.Release()
 	l.htmlCanvas.Call("addEventListener", "pointerleave", mouseLeaveCallback)
 	defer l.htmlCanvas.Call("removeEventListener", "pointerleave", mouseLeaveCallback)
 
-	mouseMoveCallback := js.FuncOf(l.onJSMouseMove)
+	mouseMoveCallback := js.FuncOf(l.invalidating(l.onJSMouseMove))
 	defer mouseMoveCallback.Release()
 	l.htmlCanvas.Call("addEventListener", "pointermove", mouseMoveCallback)
 	defer l.htmlCanvas.Call("removeEventListener", "pointermove", mouseMoveCallback)
 
-	mouseDownCallback := js.FuncOf(l.onJSMouseDown)
+	mouseDownCallback := js.FuncOf(l.invalidating(l.onJSMouseDown))
 	defer mouseDownCallback.Release()
 	l.htmlCanvas.Call("addEventListener", "pointerdown", mouseDownCallback)
 	defer l.htmlCanvas.Call("removeEventListener", "pointerdown", mouseDownCallback)
 
-	mouseUpCallback := js.FuncOf(l.onJSMouseUp)
+	mouseUpCallback := js.FuncOf(l.invalidating(l.onJSMouseUp))
 	defer mouseUpCallback.Release()
 	l.htmlCanvas.Call("addEventListener", "pointerup", mouseUpCallback)
 	defer l.htmlCanvas.Call("removeEventListener", "pointerup", mouseUpCallback)
 
-	pointerLockChangeCallback := js.FuncOf(l.onPointerLockChange)
+	pointerLockChangeCallback := js.FuncOf(l.invalidating(l.onPointerLockChange))
 	defer pointerLockChangeCallback.Release()
 	l.htmlDocument.Call("addEventListener", "pointerlockchange", pointerLockChangeCallback)
 	defer l.htmlDocument.Call("removeEventListener", "pointerlockchange", pointerLockChangeCallback)
 
-	mouseScrollCallback := js.FuncOf(l.onJSMouseWheel)
+	pointerLockErrorCallback := js.FuncOf(l.onPointerLockError)
+	defer pointerLockErrorCallback.Release()
+	l.htmlDocument.Call("addEventListener", "pointerlockerror", pointerLockErrorCallback)
+	defer l.htmlDocument.Call("removeEventListener", "pointerlockerror", pointerLockErrorCallback)
+
+	fullscreenChangeCallback := js.FuncOf(l.invalidating(l.onFullscreenChange))
+	defer fullscreenChangeCallback.Release()
+	l.htmlDocument.Call("addEventListener", "fullscreenchange", fullscreenChangeCallback)
+	defer l.htmlDocument.Call("removeEventListener", "fullscreenchange", fullscreenChangeCallback)
+	l.htmlDocument.Call("addEventListener", "webkitfullscreenchange", fullscreenChangeCallback)
+	defer l.htmlDocument.Call("removeEventListener", "webkitfullscreenchange", fullscreenChangeCallback)
+
+	mouseCancelCallback := js.FuncOf(l.invalidating(l.onJSMouseCancel))
+	defer mouseCancelCallback.Release()
+	l.htmlCanvas.Call("addEventListener", "pointercancel", mouseCancelCallback)
+	defer l.htmlCanvas.Call("removeEventListener", "pointercancel", mouseCancelCallback)
+
+	gamepadConnectedCallback := js.FuncOf(l.onJSGamepadChanged)
+	defer gamepadConnectedCallback.Release()
+	js.Global().Call("addEventListener", "gamepadconnected", gamepadConnectedCallback)
+	defer js.Global().Call("removeEventListener", "gamepadconnected", gamepadConnectedCallback)
+
+	gamepadDisconnectedCallback := js.FuncOf(l.onJSGamepadChanged)
+	defer gamepadDisconnectedCallback.Release()
+	js.Global().Call("addEventListener", "gamepaddisconnected", gamepadDisconnectedCallback)
+	defer js.Global().Call("removeEventListener", "gamepaddisconnected", gamepadDisconnectedCallback)
+
+	visibilityChangeCallback := js.FuncOf(l.invalidating(l.onJSVisibilityChange))
+	defer visibilityChangeCallback.Release()
+	l.htmlDocument.Call("addEventListener", "visibilitychange", visibilityChangeCallback)
+	defer l.htmlDocument.Call("removeEventListener", "visibilitychange", visibilityChangeCallback)
+
+	pageHideCallback := js.FuncOf(l.onJSPageHide)
+	defer pageHideCallback.Release()
+	js.Global().Call("addEventListener", "pagehide", pageHideCallback)
+	defer js.Global().Call("removeEventListener", "pagehide", pageHideCallback)
+
+	pageShowCallback := js.FuncOf(l.invalidating(l.onJSVisibilityChange))
+	defer pageShowCallback.Release()
+	js.Global().Call("addEventListener", "pageshow", pageShowCallback)
+	defer js.Global().Call("removeEventListener", "pageshow", pageShowCallback)
+
+	blurCallback := js.FuncOf(l.invalidating(l.onJSBlur))
+	defer blurCallback.Release()
+	js.Global().Call("addEventListener", "blur", blurCallback)
+	defer js.Global().Call("removeEventListener", "blur", blurCallback)
+
+	focusCallback := js.FuncOf(l.invalidating(l.onJSFocus))
+	defer focusCallback.Release()
+	js.Global().Call("addEventListener", "focus", focusCallback)
+	defer js.Global().Call("removeEventListener", "focus", focusCallback)
+
+	contextLostCallback := js.FuncOf(l.onJSContextLost)
+	defer contextLostCallback.Release()
+	l.htmlCanvas.Call("addEventListener", "webglcontextlost", contextLostCallback)
+	defer l.htmlCanvas.Call("removeEventListener", "webglcontextlost", contextLostCallback)
+
+	contextRestoredCallback := js.FuncOf(l.invalidating(l.onJSContextRestored))
+	defer contextRestoredCallback.Release()
+	l.htmlCanvas.Call("addEventListener", "webglcontextrestored", contextRestoredCallback)
+	defer l.htmlCanvas.Call("removeEventListener", "webglcontextrestored", contextRestoredCallback)
+
+	mouseScrollCallback := js.FuncOf(l.invalidating(l.onJSMouseWheel))
 	defer mouseScrollCallback.Release()
 	l.htmlCanvas.Call("addEventListener", "wheel", mouseScrollCallback)
 	defer l.htmlCanvas.Call("removeEventListener", "wheel", mouseScrollCallback)
 
+	dragOverCallback := js.FuncOf(l.onJSDragOver)
+	defer dragOverCallback.Release()
+	l.htmlCanvas.Call("addEventListener", "dragover", dragOverCallback)
+	defer l.htmlCanvas.Call("removeEventListener", "dragover", dragOverCallback)
+
+	dropCallback := js.FuncOf(l.invalidating(l.onJSDrop))
+	defer dropCallback.Release()
+	l.htmlCanvas.Call("addEventListener", "drop", dropCallback)
+	defer l.htmlCanvas.Call("removeEventListener", "drop", dropCallback)
+
+	pasteCallback := js.FuncOf(l.invalidating(l.onJSPaste))
+	defer pasteCallback.Release()
+	l.htmlDocument.Call("addEventListener", "paste", pasteCallback)
+	defer l.htmlDocument.Call("removeEventListener", "paste", pasteCallback)
+
 	closeCallback := js.FuncOf(l.onCloseRequested)
 	defer closeCallback.Release()
 	js.Global().Set("onbeforeunload", closeCallback)
 
-	l.clipboardCallback = js.FuncOf(l.onClipboardReadText)
+	l.clipboardCallback = js.FuncOf(l.invalidating(l.onClipboardReadText))
 	defer l.clipboardCallback.Release()
 
+	l.pointerLockRejectCallback = js.FuncOf(l.onPointerLockRejected)
+	defer l.pointerLockRejectCallback.Release()
+
+	l.fullscreenRejectCallback = js.FuncOf(l.onFullscreenRejected)
+	defer l.fullscreenRejectCallback.Release()
+
+	if l.autoResize {
+		l.resizer.Start()
+	}
+	defer l.resizer.Stop()
+
 	l.knownFramebufferWidth, l.knownFramebufferHeight = l.FramebufferSize()
 	l.controller.OnFramebufferResize(l, l.knownFramebufferWidth, l.knownFramebufferHeight)
 
@@ -140,7 +333,13 @@ func (l *loop) Run(audioEnabled bool) error {
 	done := make(chan error, 1)
 	var loopFunc js.Func
 	loopFunc = js.FuncOf(func(this js.Value, args []js.Value) any {
+		if l.skipFrame(args[0].Float()) {
+			js.Global().Call("requestAnimationFrame", loopFunc)
+			return true
+		}
+
 		l.checkResized()
+		l.pollGamepads()
 		l.updateGamepads()
 
 		if l.shouldStop {
@@ -152,19 +351,25 @@ func (l *loop) Run(audioEnabled bool) error {
 			return true
 		}
 
-		for _, gamepad := range l.gamepads {
-			gamepad.markDirty()
-		}
+		l.processTasks(taskBudget(l.frameInterval, l.renderDuration))
 
-		l.processTasks(taskProcessingTimeout)
+		l.runFixedUpdates()
 
-		metric.BeginFrame()
+		// NOTE: Rendering is paused while the WebGL context is lost, since
+		// all graphics resources are invalid.
+		if !l.contextLost && (l.invalidated || !l.renderOnDemand) {
+			l.invalidated = false
 
-		ctrlRegion := metric.BeginRegion("controller")
-		l.controller.OnRender(l)
-		ctrlRegion.End()
+			metric.BeginFrame()
 
-		metric.EndFrame()
+			ctrlRegion := metric.BeginRegion("controller")
+			renderStart := time.Now()
+			l.controller.OnRender(l)
+			l.renderDuration = smoothDuration(l.renderDuration, time.Since(renderStart))
+			ctrlRegion.End()
+
+			metric.EndFrame()
+		}
 
 		js.Global().Call("requestAnimationFrame", loopFunc)
 		return true
@@ -187,8 +392,16 @@ func (l *loop) SetTitle(title string) {
 }
 
 func (l *loop) SetSize(width, height int) {
-	l.htmlCanvas.Set("clientWidth", width)
-	l.htmlCanvas.Set("clientHeight", height)
+	// The clientWidth and clientHeight properties are read-only, so the
+	// size on the page is controlled through the style. Without automatic
+	// resizing, the backing store is sized to match.
+	htmlStyle := l.htmlCanvas.Get("style")
+	htmlStyle.Set("width", fmt.Sprintf("%dpx", width))
+	htmlStyle.Set("height", fmt.Sprintf("%dpx", height))
+	if !l.resizer.Active() {
+		l.htmlCanvas.Set("width", width)
+		l.htmlCanvas.Set("height", height)
+	}
 }
 
 func (l *loop) Size() (int, int) {
@@ -211,24 +424,96 @@ func (l *loop) Gamepads() [4]app.Gamepad {
 	return result
 }
 
+func (l *loop) AllGamepads() []app.Gamepad {
+	result := make([]app.Gamepad, len(l.gamepads))
+	for i, gamepad := range l.gamepads {
+		result[i] = gamepad
+	}
+	return result
+}
+
+func (l *loop) Fullscreen() bool {
+	return l.fullscreen
+}
+
+func (l *loop) SetFullscreen(fullscreen bool) {
+	if fullscreen {
+		if l.fullscreen {
+			return
+		}
+		// NOTE: Similar to pointer lock, fullscreen can only be requested
+		// as part of a user gesture.
+		l.fullscreenPending = true
+		if l.inUserGesture {
+			l.applyFullscreen()
+		}
+	} else {
+		l.fullscreenPending = false
+		if l.fullscreen {
+			if l.htmlDocument.Get("exitFullscreen").Type() == js.TypeFunction {
+				l.htmlDocument.Call("exitFullscreen")
+			} else {
+				l.htmlDocument.Call("webkitExitFullscreen")
+			}
+		}
+	}
+}
+
+func (l *loop) Storage() Storage {
+	if l.storage == nil {
+		l.storage = newBrowserStorage(l.storageName, l.Schedule)
+	}
+	return l.storage
+}
+
+func (l *loop) Fetcher() *Fetcher {
+	return l.fetcher
+}
+
+func (l *loop) Modifiers() KeyModifiers {
+	return l.modifiers
+}
+
+func (l *loop) InterpolationAlpha() float64 {
+	return l.interpolationAlpha
+}
+
+func (l *loop) Pointer(index int) (Pointer, bool) {
+	pointer, ok := l.pointers.Find(index)
+	if !ok {
+		return Pointer{}, false
+	}
+	return *pointer, true
+}
+
 func (l *loop) Schedule(fn func()) {
-	select {
-	case l.tasks <- fn:
-	default:
-		panic(fmt.Errorf("failed to queue task; queue is full"))
+	if !l.tasks.Push(fn, TaskPriorityNormal) {
+		logger.Error("Task queue is full; task dropped")
 	}
 }
 
+func (l *loop) ScheduleWithPriority(fn func(), priority TaskPriority) bool {
+	return l.tasks.Push(fn, priority)
+}
+
+func (l *loop) TaskQueueStats() TaskQueueStats {
+	return l.tasks.Stats()
+}
+
 func (l *loop) Invalidate() {
-	// Nothing to do here. Current implementation always invalidates.
+	l.invalidated = true
 }
 
 func (l *loop) CreateCursor(definition app.CursorDefinition) app.Cursor {
-	return &Cursor{
-		path:     definition.Path,
-		hotspotX: definition.HotspotX,
-		hotspotY: definition.HotspotY,
-	}
+	return newPathCursor(definition)
+}
+
+func (l *loop) CreateSystemCursor(cursor SystemCursor) app.Cursor {
+	return newSystemCursor(cursor)
+}
+
+func (l *loop) CreateImageCursor(definition ImageCursorDefinition) (app.Cursor, error) {
+	return newImageCursor(definition)
 }
 
 func (l *loop) UseCursor(cursor app.Cursor) {
@@ -247,10 +532,8 @@ func (l *loop) CursorVisible() bool {
 
 func (l *loop) SetCursorVisible(visible bool) {
 	if visible {
-		if l.cursor != nil {
-			cursorStyle := fmt.Sprintf("url(%s) %d %d, auto",
-				l.cursor.path, l.cursor.hotspotX, l.cursor.hotspotY)
-			l.htmlCanvas.Get("style").Set("cursor", cursorStyle)
+		if l.cursor != nil && l.cursor.style != "" {
+			l.htmlCanvas.Get("style").Set("cursor", l.cursor.style)
 		} else {
 			l.htmlCanvas.Get("style").Set("cursor", "auto")
 		}
@@ -264,13 +547,24 @@ func (l *loop) CursorLocked() bool {
 }
 
 func (l *loop) SetCursorLocked(locked bool) {
-	// FIXME: This should be recorded in a variable
-	// and the actual call should be made as part of a user
-	// gesture (e.g. click)
 	if locked {
-		l.htmlCanvas.Call("requestPointerLock")
+		if l.cursorLocked {
+			l.cursorLock = pointerLockStateActive
+			return
+		}
+		// NOTE: Browsers only allow pointer lock to be requested as part
+		// of a user gesture, so the request is recorded and applied once
+		// the user interacts with the canvas, unless we are already
+		// handling such an interaction.
+		l.cursorLock = pointerLockStatePending
+		if l.inUserGesture {
+			l.applyCursorLock()
+		}
 	} else {
-		l.htmlCanvas.Call("exitPointerLock")
+		l.cursorLock = pointerLockStateNone
+		if l.cursorLocked {
+			l.htmlDocument.Call("exitPointerLock")
+		}
 	}
 }
 
@@ -323,11 +617,14 @@ func (l *loop) Close() {
 }
 
 func (l *loop) checkResized() {
+	l.resizer.Poll()
+
 	framebufferWidth, framebufferHeight := l.FramebufferSize()
 	if framebufferWidth != l.knownFramebufferWidth || framebufferHeight != l.knownFramebufferHeight {
 		l.knownFramebufferWidth = framebufferWidth
 		l.knownFramebufferHeight = framebufferHeight
 		l.controller.OnFramebufferResize(l, framebufferWidth, framebufferHeight)
+		l.invalidated = true
 	}
 
 	width, height := l.Size()
@@ -335,152 +632,345 @@ func (l *loop) checkResized() {
 		l.knownWidth = width
 		l.knownHeight = height
 		l.controller.OnResize(l, width, height)
+		l.invalidated = true
 	}
 }
 
 func (l *loop) processTasks(limit time.Duration) bool {
-	startTime := time.Now()
-	for time.Since(startTime) < limit {
-		select {
-		case task := <-l.tasks:
-			task()
-		default:
-			// No more tasks, we have consumed everything there
-			// is for now.
-			return true
-		}
+	done := l.tasks.Process(limit)
+	if l.tasks.Stats().Processed > 0 {
+		l.invalidated = true
 	}
-	// We did not consume all available tasks within our time window.
-	return false
+	return done
 }
 
 func (l *loop) onJSKeyDown(this js.Value, args []js.Value) any {
 	event := args[0]
-	event.Call("preventDefault")
+	defer l.beginUserGesture()()
+	l.modifiers = modifiersFromEvent(event)
+	if isCompositionKeyEvent(event) {
+		// The key is handled by the IME and will produce composition
+		// events instead.
+		return false
+	}
 
 	var downConsumed bool
 	code := event.Get("code").String()
-	if keyCode, ok := keyboardCodeMapping[code]; ok {
+	keyCode, mapped := keyboardCodeMapping[code]
+	if mapped {
 		action := app.KeyboardActionDown
 		if event.Get("repeat").Bool() {
 			action = app.KeyboardActionRepeat
 		}
+		l.pressedKeys[keyCode] = struct{}{}
 		downConsumed = l.controller.OnKeyboardEvent(l, app.KeyboardEvent{
 			Action: action,
 			Code:   keyCode,
 		})
 	}
 
-	// NOTE: JS has the keypress callback deprecated so we fake it here.
+	// NOTE: While text input is enabled, typed text is delivered through
+	// input and composition events of the text input element instead.
 	var pressConsumed bool
-	key := []rune(event.Get("key").String())
-	if len(key) == 1 {
-		pressConsumed = l.controller.OnKeyboardEvent(l, app.KeyboardEvent{
-			Action:    app.KeyboardActionType,
-			Character: key[0],
-		})
+	if !l.textInput.Enabled() {
+		// NOTE: JS has the keypress callback deprecated so we fake it here.
+		if ch, ok := typedCharacter(event); ok {
+			pressConsumed = l.controller.OnKeyboardEvent(l, app.KeyboardEvent{
+				Action:    app.KeyboardActionType,
+				Character: ch,
+			})
+		}
+	}
+
+	consumed := downConsumed || pressConsumed
+	if mapped {
+		l.preventKeyboardDefault(event, keyCode, consumed)
+	} else if consumed {
+		// Keys that cannot be reported are otherwise left to the browser.
+		event.Call("preventDefault")
 	}
+	return consumed
+}
 
-	return downConsumed || pressConsumed
+// preventKeyboardDefault stops the browser from handling the keyboard
+// event, unless it was not consumed and the passthrough policy allows it.
+// While text input is enabled, the event is needed to produce text, so
+// only focus navigation is prevented.
+func (l *loop) preventKeyboardDefault(event js.Value, keyCode app.KeyCode, consumed bool) {
+	if l.textInput.Enabled() {
+		if keyCode == app.KeyCodeTab {
+			event.Call("preventDefault")
+		}
+		return
+	}
+	if consumed || !l.passthrough(keyCode, l.modifiers) {
+		event.Call("preventDefault")
+	}
 }
 
 func (l *loop) onJSKeyUp(this js.Value, args []js.Value) any {
 	event := args[0]
-	event.Call("preventDefault")
+	l.modifiers = modifiersFromEvent(event)
+	if isCompositionKeyEvent(event) {
+		return false
+	}
 
 	code := event.Get("code").String()
 	keyCode, ok := keyboardCodeMapping[code]
 	if !ok {
 		return false
 	}
+	delete(l.pressedKeys, keyCode)
 
-	return l.controller.OnKeyboardEvent(l, app.KeyboardEvent{
+	consumed := l.controller.OnKeyboardEvent(l, app.KeyboardEvent{
 		Action: app.KeyboardActionUp,
 		Code:   keyCode,
 	})
+	l.preventKeyboardDefault(event, keyCode, consumed)
+	return consumed
 }
 
 func (l *loop) onJSMouseEnter(this js.Value, args []js.Value) any {
 	event := args[0]
+	l.modifiers = modifiersFromEvent(event)
+	pointer := l.pointers.Track(event)
+	x, y := l.pointerPosition(pointer, event)
 	return l.controller.OnMouseEvent(l, app.MouseEvent{
-		Index:  0,
+		Index:  pointer.Index,
 		Action: app.MouseActionEnter,
-		X:      int(event.Get("offsetX").Float()),
-		Y:      int(event.Get("offsetY").Float()),
+		X:      x,
+		Y:      y,
 	})
 }
 
 func (l *loop) onJSMouseLeave(this js.Value, args []js.Value) any {
 	event := args[0]
+	l.modifiers = modifiersFromEvent(event)
+	// NOTE: Touch and pen pointers are released when lifted, so the leave
+	// event that follows should not track them again under a new index.
+	pointer, ok := l.pointers.Lookup(event)
+	if !ok {
+		return false
+	}
+	defer l.pointers.Release(pointer)
+	x, y := l.pointerPosition(pointer, event)
 	return l.controller.OnMouseEvent(l, app.MouseEvent{
-		Index:  0,
+		Index:  pointer.Index,
 		Action: app.MouseActionLeave,
-		X:      int(event.Get("offsetX").Float()),
-		Y:      int(event.Get("offsetY").Float()),
+		X:      x,
+		Y:      y,
 	})
 }
 
 func (l *loop) onJSMouseMove(this js.Value, args []js.Value) any {
 	event := args[0]
+	l.modifiers = modifiersFromEvent(event)
+	pointer := l.pointers.Track(event)
+	if l.cursorLocked {
+		l.lockedMouseX += pointer.MovementX
+		l.lockedMouseY += pointer.MovementY
+	}
+	x, y := l.pointerPosition(pointer, event)
 	return l.controller.OnMouseEvent(l, app.MouseEvent{
-		Index:  0,
+		Index:  pointer.Index,
 		Action: app.MouseActionMove,
-		X:      int(event.Get("offsetX").Float()),
-		Y:      int(event.Get("offsetY").Float()),
+		X:      x,
+		Y:      y,
 	})
 }
 
 func (l *loop) onJSMouseDown(this js.Value, args []js.Value) any {
 	event := args[0]
+	l.modifiers = modifiersFromEvent(event)
+	defer l.beginUserGesture()()
 	l.htmlCanvas.Call("setPointerCapture", event.Get("pointerId"))
 
+	pointer := l.pointers.Track(event)
+	button := mouseButtonMapping[event.Get("button").Int()]
+	pointer.pressed = true
+	pointer.pressedButton = button
+
 	// NOTE: Don't prevent this event or the user will never be able
 	// to select the canvas for keyboard events.
-	buttonIndex := event.Get("button").Int()
+	x, y := l.pointerPosition(pointer, event)
 	return l.controller.OnMouseEvent(l, app.MouseEvent{
-		Index:  0,
+		Index:  pointer.Index,
 		Action: app.MouseActionDown,
-		X:      int(event.Get("offsetX").Float()),
-		Y:      int(event.Get("offsetY").Float()),
-		Button: mouseButtonMapping[buttonIndex],
+		X:      x,
+		Y:      y,
+		Button: button,
 	})
 }
 
 func (l *loop) onJSMouseUp(this js.Value, args []js.Value) any {
 	event := args[0]
+	l.modifiers = modifiersFromEvent(event)
+	defer l.beginUserGesture()()
+	// Clicking on the canvas moves focus away from the text input element.
+	defer l.textInput.Focus()
 	event.Call("preventDefault")
-	buttonIndex := event.Get("button").Int()
+
+	pointer := l.pointers.Track(event)
+	pointer.pressed = false
+	if pointer.Type != PointerTypeMouse {
+		// Touch and pen contacts end when lifted, even though the
+		// browser might not dispatch a leave event right away.
+		defer l.pointers.Release(pointer)
+	}
+	x, y := l.pointerPosition(pointer, event)
+	return l.controller.OnMouseEvent(l, app.MouseEvent{
+		Index:  pointer.Index,
+		Action: app.MouseActionUp,
+		X:      x,
+		Y:      y,
+		Button: mouseButtonMapping[event.Get("button").Int()],
+	})
+}
+
+func (l *loop) onJSMouseCancel(this js.Value, args []js.Value) any {
+	event := args[0]
+	l.modifiers = modifiersFromEvent(event)
+	pointer := l.pointers.Track(event)
+	defer l.pointers.Release(pointer)
+	if !pointer.pressed {
+		return false
+	}
+
+	// The browser took over the pointer (e.g. for scrolling or due to an
+	// orientation change) so we release any pressed button, otherwise the
+	// controller would consider it to be held forever.
+	pointer.pressed = false
+	x, y := l.pointerPosition(pointer, event)
 	return l.controller.OnMouseEvent(l, app.MouseEvent{
-		Index:  0,
+		Index:  pointer.Index,
 		Action: app.MouseActionUp,
-		X:      int(event.Get("offsetX").Float()),
-		Y:      int(event.Get("offsetY").Float()),
-		Button: mouseButtonMapping[buttonIndex],
+		X:      x,
+		Y:      y,
+		Button: pointer.pressedButton,
 	})
 }
 
 func (l *loop) onJSMouseWheel(this js.Value, args []js.Value) any {
 	event := args[0]
+	l.modifiers = modifiersFromEvent(event)
 	event.Call("preventDefault")
+	x, y := l.mousePosition(event)
 	return l.controller.OnMouseEvent(l, app.MouseEvent{
-		Index:   0,
+		Index:   l.mousePointerIndex(),
 		Action:  app.MouseActionScroll,
-		X:       int(event.Get("offsetX").Float()),
-		Y:       int(event.Get("offsetY").Float()),
+		X:       x,
+		Y:       y,
 		ScrollX: event.Get("deltaX").Float() / 100.0,
 		ScrollY: event.Get("deltaY").Float() / 100.0,
 	})
 }
 
+func (l *loop) mousePointerIndex() int {
+	if pointer, ok := l.pointers.FindByType(PointerTypeMouse); ok {
+		return pointer.Index
+	}
+	return 0
+}
+
 func (l *loop) onPointerLockChange(this js.Value, args []js.Value) any {
 	if l.htmlDocument.Get("pointerLockElement").Equal(l.htmlCanvas) {
+		if !l.cursorLocked {
+			l.lockedMouseX = l.lastMouseX
+			l.lockedMouseY = l.lastMouseY
+		}
 		l.cursorLocked = true
+		l.cursorLock = pointerLockStateActive
 	} else {
 		l.cursorLocked = false
+		if l.cursorLock == pointerLockStateActive {
+			// The browser released the lock (e.g. the user pressed Escape),
+			// so we should not reacquire it on the next click.
+			l.cursorLock = pointerLockStateNone
+		}
+	}
+	return js.Null()
+}
+
+func (l *loop) onPointerLockError(this js.Value, args []js.Value) any {
+	logger.Warn("Pointer lock request was rejected")
+	if l.cursorLock == pointerLockStateRequested {
+		// Try again on the next user gesture.
+		l.cursorLock = pointerLockStatePending
+	}
+	return js.Null()
+}
+
+func (l *loop) onPointerLockRejected(this js.Value, args []js.Value) any {
+	if l.cursorLock != pointerLockStateRequested {
+		return js.Null()
+	}
+	if l.unadjustedLockMotion && len(args) > 0 && args[0].Get("name").String() == "NotSupportedError" {
+		logger.Warn("Unadjusted cursor movement is not supported")
+		l.unadjustedLockMotion = false
+		if l.inUserGesture {
+			l.applyCursorLock()
+			return js.Null()
+		}
 	}
+	l.cursorLock = pointerLockStatePending
 	return js.Null()
 }
 
+// beginUserGesture marks the start of a user gesture handler and applies
+// any deferred requests that need such a gesture. The returned function
+// should be called once the handler completes.
+func (l *loop) beginUserGesture() func() {
+	l.inUserGesture = true
+	if l.cursorLock == pointerLockStatePending {
+		l.applyCursorLock()
+	}
+	if l.fullscreenPending {
+		l.applyFullscreen()
+	}
+	return l.endUserGesture
+}
+
+func (l *loop) endUserGesture() {
+	l.inUserGesture = false
+}
+
+func (l *loop) applyCursorLock() {
+	l.cursorLock = pointerLockStateRequested
+	var jsPromise js.Value
+	if l.unadjustedLockMotion {
+		jsPromise = l.htmlCanvas.Call("requestPointerLock", map[string]any{
+			"unadjustedMovement": true,
+		})
+	} else {
+		jsPromise = l.htmlCanvas.Call("requestPointerLock")
+	}
+	// NOTE: Older browsers don't return a promise and report
+	// failures through the pointerlockerror event only.
+	if jsPromise.Type() == js.TypeObject && jsPromise.Get("catch").Type() == js.TypeFunction {
+		jsPromise.Call("catch", l.pointerLockRejectCallback)
+	}
+}
+
+// pointerPosition returns the position of the specified pointer and
+// records it, so that it can be used for synthetic events.
+func (l *loop) pointerPosition(pointer *Pointer, event js.Value) (int, int) {
+	pointer.x, pointer.y = l.mousePosition(event)
+	return pointer.x, pointer.y
+}
+
+// mousePosition returns the cursor position for the specified pointer
+// event. While the cursor is locked, the browser keeps reporting the
+// position at which the lock was acquired, so a virtual position that is
+// driven by the relative movement is returned instead.
+func (l *loop) mousePosition(event js.Value) (int, int) {
+	if l.cursorLocked {
+		return int(l.lockedMouseX), int(l.lockedMouseY)
+	}
+	l.lastMouseX = event.Get("offsetX").Float()
+	l.lastMouseY = event.Get("offsetY").Float()
+	return int(l.lastMouseX), int(l.lastMouseY)
+}
+
 func (l *loop) onCloseRequested(this js.Value, args []js.Value) any {
 	if !l.controller.OnCloseRequested(l) {
 		return "reject"
@@ -499,12 +989,113 @@ func (l *loop) onClipboardReadText(this js.Value, args []js.Value) any {
 	return nil
 }
 
+func (l *loop) onJSGamepadChanged(this js.Value, args []js.Value) any {
+	l.gamepadsChanged = true
+	return nil
+}
+
+// pollGamepads fetches the latest gamepad snapshots from the browser and
+// assigns them to slots. While no gamepads are connected, this is only
+// done when a connection event has been received or occasionally, in case
+// the browser did not report a gamepad through an event.
+func (l *loop) pollGamepads() {
+	if l.connectedGamepads == 0 && !l.gamepadsChanged && time.Since(l.lastGamepadPoll) < gamepadDiscoveryInterval {
+		return
+	}
+	l.gamepadsChanged = false
+	l.lastGamepadPoll = time.Now()
+
+	jsGamepads := js.Global().Get("navigator").Call("getGamepads")
+	if jsGamepads.IsUndefined() || jsGamepads.IsNull() {
+		return
+	}
+
+	type browserGamepad struct {
+		index int
+		id    string
+		value js.Value
+	}
+	var connected []browserGamepad
+	for browserIndex := range jsGamepads.Length() {
+		jsGamepad := jsGamepads.Index(browserIndex)
+		if jsGamepad.IsUndefined() || jsGamepad.IsNull() || !jsGamepad.Get("connected").Bool() {
+			continue
+		}
+		connected = append(connected, browserGamepad{
+			index: browserIndex,
+			id:    jsGamepad.Get("id").String(),
+			value: jsGamepad,
+		})
+	}
+
+	// NOTE: Slots whose gamepad is gone are unassigned before matching, so
+	// that they can be reused by a gamepad that took over the browser index
+	// during the same poll.
+	for _, gamepad := range l.gamepads {
+		isConnected := slices.ContainsFunc(connected, func(candidate browserGamepad) bool {
+			return candidate.index == gamepad.browserIndex && candidate.id == gamepad.id
+		})
+		if !isConnected {
+			gamepad.unassign()
+		}
+	}
+
+	assigned := make([]bool, len(l.gamepads), len(l.gamepads)+1)
+	l.connectedGamepads = 0
+	for _, candidate := range connected {
+		slot := l.gamepadSlot(candidate.index, candidate.id, assigned)
+		if slot == len(l.gamepads) {
+			l.gamepads = append(l.gamepads, newGamepad(l.platform.OS()))
+			l.gamepadStates = append(l.gamepadStates, gamepadState{})
+			assigned = append(assigned, false)
+		}
+		l.gamepads[slot].assign(candidate.index, candidate.value)
+		assigned[slot] = true
+		l.connectedGamepads++
+	}
+}
+
+// gamepadSlot returns the slot that should hold the browser gamepad with
+// the specified index and id. Slots are reused in the following order of
+// preference: the slot that already holds the gamepad, a free slot that
+// previously held a gamepad with the same id, a slot that has never been
+// used, and finally any free slot. If no slot is available, the index of
+// a new slot is returned.
+func (l *loop) gamepadSlot(browserIndex int, id string, assigned []bool) int {
+	for slot, gamepad := range l.gamepads {
+		if !assigned[slot] && gamepad.browserIndex == browserIndex && gamepad.id == id {
+			return slot
+		}
+	}
+	isFree := func(slot int) bool {
+		return !assigned[slot] && l.gamepads[slot].browserIndex < 0
+	}
+	for slot, gamepad := range l.gamepads {
+		if isFree(slot) && gamepad.id == id {
+			return slot
+		}
+	}
+	for slot, gamepad := range l.gamepads {
+		if isFree(slot) && gamepad.id == "" {
+			return slot
+		}
+	}
+	for slot := range l.gamepads {
+		if isFree(slot) {
+			return slot
+		}
+	}
+	return len(l.gamepads)
+}
+
 func (l *loop) updateGamepads() {
-	elapsedTime := time.Since(l.lastGamepadUpdate)
+	now := time.Now()
+	elapsedTime := now.Sub(l.lastGamepadUpdate)
 	for i, gamepad := range l.gamepads {
 		l.updateGamepad(i, gamepad, elapsedTime)
+		gamepad.updateHaptics(now)
 	}
-	l.lastGamepadUpdate = time.Now()
+	l.lastGamepadUpdate = now
 }
 
 func (l *loop) updateGamepad(index int, gamepad *Gamepad, elapsedTime time.Duration) {
@@ -513,13 +1104,13 @@ func (l *loop) updateGamepad(index int, gamepad *Gamepad, elapsedTime time.Durat
 	connected := gamepad.Connected()
 	switch {
 	case connected && !state.connected:
-		l.controller.OnGamepadEvent(l, app.GamepadEvent{
+		l.onGamepadEvent(app.GamepadEvent{
 			Index:   index,
 			Gamepad: gamepad,
 			Action:  app.GamepadActionConnected,
 		})
 	case !connected && state.connected:
-		l.controller.OnGamepadEvent(l, app.GamepadEvent{
+		l.onGamepadEvent(app.GamepadEvent{
 			Index:   index,
 			Gamepad: gamepad,
 			Action:  app.GamepadActionDisconnected,
@@ -547,7 +1138,7 @@ func (l *loop) updateGamepad(index int, gamepad *Gamepad, elapsedTime time.Durat
 	for stick, stickValue := range newStickValues {
 		oldStickValue := state.gamepadStickValues[stick]
 		if !dprec.Eq(stickValue[0], oldStickValue[0]) || !dprec.Eq(stickValue[1], oldStickValue[1]) {
-			l.controller.OnGamepadEvent(l, app.GamepadEvent{
+			l.onGamepadEvent(app.GamepadEvent{
 				Index:   index,
 				Gamepad: gamepad,
 				Action:  app.GamepadActionStickMove,
@@ -591,7 +1182,7 @@ func (l *loop) updateGamepad(index int, gamepad *Gamepad, elapsedTime time.Durat
 		case oldPressed && pressed:
 			if state.gamepadButtonCooldown[button] <= 0 {
 				state.gamepadButtonCooldown[button] = app.GamepadRepeatInterval
-				l.controller.OnGamepadEvent(l, app.GamepadEvent{
+				l.onGamepadEvent(app.GamepadEvent{
 					Index:   index,
 					Gamepad: gamepad,
 					Action:  app.GamepadActionButtonRepeat,
@@ -600,14 +1191,14 @@ func (l *loop) updateGamepad(index int, gamepad *Gamepad, elapsedTime time.Durat
 			}
 		case !oldPressed && pressed:
 			state.gamepadButtonCooldown[button] = app.GamepadRepeatDelay
-			l.controller.OnGamepadEvent(l, app.GamepadEvent{
+			l.onGamepadEvent(app.GamepadEvent{
 				Index:   index,
 				Gamepad: gamepad,
 				Action:  app.GamepadActionButtonDown,
 				Button:  app.GamepadButton(button),
 			})
 		case oldPressed && !pressed:
-			l.controller.OnGamepadEvent(l, app.GamepadEvent{
+			l.onGamepadEvent(app.GamepadEvent{
 				Index:   index,
 				Gamepad: gamepad,
 				Action:  app.GamepadActionButtonUp,
@@ -617,3 +1208,243 @@ func (l *loop) updateGamepad(index int, gamepad *Gamepad, elapsedTime time.Durat
 		state.gamepadButtonPressed[button] = pressed
 	}
 }
+
+func (l *loop) applyFullscreen() {
+	l.fullscreenPending = false
+	var jsPromise js.Value
+	switch {
+	case l.htmlCanvas.Get("requestFullscreen").Type() == js.TypeFunction:
+		jsPromise = l.htmlCanvas.Call("requestFullscreen")
+	case l.htmlCanvas.Get("webkitRequestFullscreen").Type() == js.TypeFunction:
+		jsPromise = l.htmlCanvas.Call("webkitRequestFullscreen")
+	default:
+		logger.Warn("Fullscreen API not supported")
+		return
+	}
+	if jsPromise.Type() == js.TypeObject && jsPromise.Get("catch").Type() == js.TypeFunction {
+		jsPromise.Call("catch", l.fullscreenRejectCallback)
+	}
+}
+
+func (l *loop) onFullscreenRejected(this js.Value, args []js.Value) any {
+	logger.Warn("Fullscreen or keyboard lock request was rejected")
+	return js.Null()
+}
+
+func (l *loop) onFullscreenChange(this js.Value, args []js.Value) any {
+	jsElement := l.htmlDocument.Get("fullscreenElement")
+	if jsElement.IsUndefined() {
+		jsElement = l.htmlDocument.Get("webkitFullscreenElement")
+	}
+	fullscreen := jsElement.Equal(l.htmlCanvas)
+	if fullscreen == l.fullscreen {
+		return js.Null()
+	}
+	l.fullscreen = fullscreen
+
+	// The backing store needs to follow the screen size while in fullscreen,
+	// even if automatic resizing is not enabled.
+	if !l.autoResize {
+		if fullscreen {
+			l.windowedWidth, l.windowedHeight = l.FramebufferSize()
+			l.resizer.Start()
+		} else {
+			l.resizer.Stop()
+			l.htmlCanvas.Set("width", l.windowedWidth)
+			l.htmlCanvas.Set("height", l.windowedHeight)
+		}
+	}
+
+	if l.keyboardLock {
+		jsKeyboard := js.Global().Get("navigator").Get("keyboard")
+		if !jsKeyboard.IsUndefined() && jsKeyboard.Get("lock").Type() == js.TypeFunction {
+			if fullscreen {
+				jsPromise := jsKeyboard.Call("lock")
+				jsPromise.Call("catch", l.fullscreenRejectCallback)
+			} else {
+				jsKeyboard.Call("unlock")
+			}
+		}
+	}
+
+	l.checkResized()
+	return js.Null()
+}
+
+func (l *loop) onJSContextLost(this js.Value, args []js.Value) any {
+	// Preventing the default behavior signals to the browser that the
+	// application is able to handle a context restoration.
+	args[0].Call("preventDefault")
+	logger.Warn("WebGL context lost")
+	l.contextLost = true
+	if controller, ok := l.controller.(GraphicsContextController); ok {
+		controller.OnContextLost(l)
+	}
+	return nil
+}
+
+func (l *loop) onJSContextRestored(this js.Value, args []js.Value) any {
+	logger.Info("WebGL context restored")
+	enableGLExtensions(l.glExtensions)
+	if api, ok := l.renderAPI.(*jsrender.API); ok {
+		api.Restore()
+	}
+	l.contextLost = false
+	if controller, ok := l.controller.(GraphicsContextController); ok {
+		controller.OnContextRestored(l)
+	}
+	return nil
+}
+
+func (l *loop) onJSVisibilityChange(this js.Value, args []js.Value) any {
+	l.setVisible(l.htmlDocument.Get("visibilityState").String() != "hidden")
+	return nil
+}
+
+func (l *loop) onJSPageHide(this js.Value, args []js.Value) any {
+	l.setVisible(false)
+	return nil
+}
+
+func (l *loop) setVisible(visible bool) {
+	if visible == l.visible {
+		return
+	}
+	l.visible = visible
+	if api, ok := l.audioAPI.(*jsaudio.API); ok {
+		if visible {
+			api.Resume()
+		} else {
+			api.Suspend()
+		}
+	}
+	if controller, ok := l.controller.(LifecycleController); ok {
+		controller.OnVisibilityChange(l, visible)
+	}
+}
+
+func (l *loop) onJSBlur(this js.Value, args []js.Value) any {
+	if !l.focused {
+		return nil
+	}
+	l.focused = false
+	// Key and button releases are not delivered while the page does not
+	// have focus, so anything that is held is released now, otherwise the
+	// controller would consider it to be held forever.
+	l.releaseInputs()
+	if controller, ok := l.controller.(LifecycleController); ok {
+		controller.OnFocusChange(l, false)
+	}
+	return nil
+}
+
+func (l *loop) onJSFocus(this js.Value, args []js.Value) any {
+	if l.focused {
+		return nil
+	}
+	l.focused = true
+	if controller, ok := l.controller.(LifecycleController); ok {
+		controller.OnFocusChange(l, true)
+	}
+	return nil
+}
+
+func (l *loop) releaseInputs() {
+	for keyCode := range l.pressedKeys {
+		l.controller.OnKeyboardEvent(l, app.KeyboardEvent{
+			Action: app.KeyboardActionUp,
+			Code:   keyCode,
+		})
+	}
+	clear(l.pressedKeys)
+	l.modifiers = 0
+
+	for _, pointer := range l.pointers.Pressed() {
+		pointer.pressed = false
+		l.controller.OnMouseEvent(l, app.MouseEvent{
+			Index:  pointer.Index,
+			Action: app.MouseActionUp,
+			X:      pointer.x,
+			Y:      pointer.y,
+			Button: pointer.pressedButton,
+		})
+	}
+}
+
+// runFixedUpdates dispatches as many fixed updates as fit in the real
+// time that has elapsed since the previous frame.
+func (l *loop) runFixedUpdates() {
+	controller, ok := l.controller.(FixedUpdateController)
+	if !ok || l.updateInterval <= 0 {
+		return
+	}
+	now := time.Now()
+	if !l.lastUpdateTime.IsZero() {
+		l.updateAccumulator += now.Sub(l.lastUpdateTime)
+	}
+	l.lastUpdateTime = now
+
+	for steps := 0; l.updateAccumulator >= l.updateInterval; steps++ {
+		if steps >= l.maxUpdateSteps {
+			// The updates cannot keep up (or the page was in the background),
+			// so the excess time is dropped instead of being caught up on.
+			l.updateAccumulator %= l.updateInterval
+			break
+		}
+		controller.OnFixedUpdate(l, l.updateInterval)
+		l.invalidated = true
+		l.updateAccumulator -= l.updateInterval
+	}
+	l.interpolationAlpha = float64(l.updateAccumulator) / float64(l.updateInterval)
+}
+
+// skipFrame returns whether the animation frame with the specified
+// timestamp (in milliseconds) should be skipped in order to honor the
+// frame rate limit.
+func (l *loop) skipFrame(timestamp float64) bool {
+	if l.maxFrameRate <= 0 {
+		l.measureFrame(timestamp)
+		return false
+	}
+	// NOTE: A small tolerance is allowed, since animation frame timestamps
+	// jitter and a 60 FPS limit should not skip frames on a 60Hz display.
+	const toleranceMillis = 1.0
+	frameMillis := 1000.0 / float64(l.maxFrameRate)
+	if timestamp-l.lastFrameTimestamp < frameMillis-toleranceMillis {
+		return true
+	}
+	l.measureFrame(timestamp)
+	return false
+}
+
+// measureFrame tracks the interval between processed animation frames.
+func (l *loop) measureFrame(timestamp float64) {
+	if l.lastFrameTimestamp > 0.0 {
+		interval := time.Duration((timestamp - l.lastFrameTimestamp) * float64(time.Millisecond))
+		l.frameInterval = smoothDuration(l.frameInterval, interval)
+	}
+	l.lastFrameTimestamp = timestamp
+}
+
+// smoothDuration blends a new measurement into a running average, so
+// that individual slow frames do not cause large fluctuations.
+func smoothDuration(average, sample time.Duration) time.Duration {
+	if average == 0 {
+		return sample
+	}
+	return (average*7 + sample) / 8
+}
+
+// invalidating wraps a JavaScript event handler so that the window is
+// invalidated whenever the event occurs.
+func (l *loop) invalidating(handler func(this js.Value, args []js.Value) any) func(this js.Value, args []js.Value) any {
+	return func(this js.Value, args []js.Value) any {
+		l.invalidated = true
+		return handler(this, args)
+	}
+}
+
+func (l *loop) onGamepadEvent(event app.GamepadEvent) bool {
+	l.invalidated = true
+	return l.controller.OnGamepadEvent(l, event)
+}
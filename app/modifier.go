@@ -0,0 +1,60 @@
+package app
+
+import "github.com/mokiat/lacking/app"
+
+// KeyModifiers is a set of keyboard modifiers.
+type KeyModifiers uint8
+
+const (
+	// KeyModifierShift indicates that a Shift key is held.
+	KeyModifierShift KeyModifiers = 1 << iota
+
+	// KeyModifierControl indicates that a Control key is held.
+	KeyModifierControl
+
+	// KeyModifierAlt indicates that an Alt (Option on macOS) key is held.
+	KeyModifierAlt
+
+	// KeyModifierMeta indicates that a Meta (Command on macOS, Windows on
+	// Windows) key is held.
+	KeyModifierMeta
+
+	// KeyModifierCapsLock indicates that Caps Lock is active.
+	KeyModifierCapsLock
+
+	// KeyModifierAltGraph indicates that an AltGr key is held.
+	KeyModifierAltGraph
+)
+
+// Has returns whether all of the specified modifiers are part of the set.
+func (m KeyModifiers) Has(modifiers KeyModifiers) bool {
+	return m&modifiers == modifiers
+}
+
+// KeyboardPassthroughFunc decides whether a keyboard event that was not
+// consumed by the controller should be handled by the browser (e.g. to
+// reload the page or to open the developer tools).
+type KeyboardPassthroughFunc func(code app.KeyCode, modifiers KeyModifiers) bool
+
+// DefaultKeyboardPassthrough lets common browser shortcuts through: page
+// reload, fullscreen, developer tools and paste.
+func DefaultKeyboardPassthrough(code app.KeyCode, modifiers KeyModifiers) bool {
+	shortcut := modifiers.Has(KeyModifierControl) || modifiers.Has(KeyModifierMeta)
+	switch code {
+	case app.KeyCodeF5, app.KeyCodeF11, app.KeyCodeF12:
+		return true
+	case app.KeyCodeR, app.KeyCodeV:
+		return shortcut
+	case app.KeyCodeI, app.KeyCodeJ, app.KeyCodeC:
+		// Ctrl+Shift+I on Windows and Linux and Cmd+Option+I on macOS.
+		return shortcut && (modifiers.Has(KeyModifierShift) || modifiers.Has(KeyModifierAlt))
+	default:
+		return false
+	}
+}
+
+// NoKeyboardPassthrough prevents the browser from handling any keyboard
+// event for which there is an app.KeyCode.
+func NoKeyboardPassthrough(code app.KeyCode, modifiers KeyModifiers) bool {
+	return false
+}
@@ -0,0 +1,214 @@
+//go:build js && wasm
+
+package app
+
+import (
+	"syscall/js"
+
+	"github.com/mokiat/lacking/app"
+)
+
+// PointerType represents the kind of device that controls a pointer.
+type PointerType int
+
+const (
+	// PointerTypeMouse indicates a mouse or a touchpad.
+	PointerTypeMouse PointerType = iota
+
+	// PointerTypePen indicates a pen or a stylus.
+	PointerTypePen
+
+	// PointerTypeTouch indicates a finger on a touch screen.
+	PointerTypeTouch
+)
+
+// Pointer holds the most recent state of an active pointer.
+type Pointer struct {
+
+	// Index is the value that is used for app.MouseEvent.Index for
+	// events that originate from this pointer.
+	Index int
+
+	// Type specifies the kind of device that controls the pointer.
+	Type PointerType
+
+	// Primary specifies whether this is the primary pointer of its type
+	// (e.g. the first finger that touched the screen).
+	Primary bool
+
+	// Pressure is the normalized pressure of the pointer in the range
+	// [0.0, 1.0]. Devices that do not support pressure report 0.5 while
+	// a button is pressed and 0.0 otherwise.
+	Pressure float64
+
+	// TiltX is the angle in degrees between the Y-Z plane and the plane
+	// containing the pen axis and the Y axis.
+	TiltX float64
+
+	// TiltY is the angle in degrees between the X-Z plane and the plane
+	// containing the pen axis and the X axis.
+	TiltY float64
+
+	// Twist is the clockwise rotation of the pen around its own axis in
+	// degrees.
+	Twist float64
+
+	// Width is the width of the contact geometry in CSS pixels.
+	Width float64
+
+	// Height is the height of the contact geometry in CSS pixels.
+	Height float64
+
+	// MovementX is the horizontal distance the pointer has moved since
+	// the previous move event. Unlike the event position, this keeps
+	// changing while the cursor is locked.
+	MovementX float64
+
+	// MovementY is the vertical distance the pointer has moved since
+	// the previous move event. Unlike the event position, this keeps
+	// changing while the cursor is locked.
+	MovementY float64
+
+	id            int
+	x             int
+	y             int
+	pressed       bool
+	pressedButton app.MouseButton
+}
+
+func newPointerTracker() *pointerTracker {
+	return &pointerTracker{
+		pointers: make(map[int]*Pointer),
+	}
+}
+
+// pointerTracker assigns stable mouse event indices to browser pointers
+// based on their pointerId.
+type pointerTracker struct {
+	pointers map[int]*Pointer
+}
+
+// Track returns the pointer that is associated with the specified
+// PointerEvent, allocating one if this is the first time the pointer
+// is seen, and updates its state from the event.
+func (t *pointerTracker) Track(event js.Value) *Pointer {
+	id := event.Get("pointerId").Int()
+	pointer, ok := t.pointers[id]
+	if !ok {
+		pointer = &Pointer{
+			Index: t.freeIndex(),
+			id:    id,
+		}
+		t.pointers[id] = pointer
+	}
+	pointer.update(event)
+	return pointer
+}
+
+// Lookup returns the pointer that is associated with the specified
+// PointerEvent and updates its state from the event. Unlike Track, it
+// does not allocate a pointer if the pointer is not tracked (e.g. because
+// it was already released).
+func (t *pointerTracker) Lookup(event js.Value) (*Pointer, bool) {
+	pointer, ok := t.pointers[event.Get("pointerId").Int()]
+	if !ok {
+		return nil, false
+	}
+	pointer.update(event)
+	return pointer, true
+}
+
+func (p *Pointer) update(event js.Value) {
+	p.Type = pointerTypeFromString(event.Get("pointerType").String())
+	p.Primary = event.Get("isPrimary").Bool()
+	p.Pressure = jsFloatOrDefault(event.Get("pressure"), 0.0)
+	p.TiltX = jsFloatOrDefault(event.Get("tiltX"), 0.0)
+	p.TiltY = jsFloatOrDefault(event.Get("tiltY"), 0.0)
+	p.Twist = jsFloatOrDefault(event.Get("twist"), 0.0)
+	p.Width = jsFloatOrDefault(event.Get("width"), 1.0)
+	p.Height = jsFloatOrDefault(event.Get("height"), 1.0)
+	p.MovementX = jsFloatOrDefault(event.Get("movementX"), 0.0)
+	p.MovementY = jsFloatOrDefault(event.Get("movementY"), 0.0)
+}
+
+// Release stops tracking the specified pointer, making its index
+// available for new pointers.
+func (t *pointerTracker) Release(pointer *Pointer) {
+	delete(t.pointers, pointer.id)
+}
+
+// Find returns the active pointer that has the specified index.
+func (t *pointerTracker) Find(index int) (*Pointer, bool) {
+	for _, pointer := range t.pointers {
+		if pointer.Index == index {
+			return pointer, true
+		}
+	}
+	return nil, false
+}
+
+// FindByType returns an active pointer of the specified type.
+func (t *pointerTracker) FindByType(pointerType PointerType) (*Pointer, bool) {
+	for _, pointer := range t.pointers {
+		if pointer.Type == pointerType {
+			return pointer, true
+		}
+	}
+	return nil, false
+}
+
+// Pressed returns all active pointers that have a button pressed.
+func (t *pointerTracker) Pressed() []*Pointer {
+	var result []*Pointer
+	for _, pointer := range t.pointers {
+		if pointer.pressed {
+			result = append(result, pointer)
+		}
+	}
+	return result
+}
+
+func (t *pointerTracker) freeIndex() int {
+	for index := 0; ; index++ {
+		if _, ok := t.Find(index); !ok {
+			return index
+		}
+	}
+}
+
+func pointerTypeFromString(value string) PointerType {
+	switch value {
+	case "pen":
+		return PointerTypePen
+	case "touch":
+		return PointerTypeTouch
+	default:
+		return PointerTypeMouse
+	}
+}
+
+func jsFloatOrDefault(value js.Value, defaultValue float64) float64 {
+	if value.Type() != js.TypeNumber {
+		return defaultValue
+	}
+	return value.Float()
+}
+
+// pointerLockState tracks the progress of a cursor lock request.
+type pointerLockState int
+
+const (
+	// pointerLockStateNone indicates that no cursor lock is desired.
+	pointerLockStateNone pointerLockState = iota
+
+	// pointerLockStatePending indicates that a cursor lock is desired
+	// and should be requested on the next user gesture.
+	pointerLockStatePending
+
+	// pointerLockStateRequested indicates that a cursor lock has been
+	// requested from the browser and a response is awaited.
+	pointerLockStateRequested
+
+	// pointerLockStateActive indicates that the cursor is locked.
+	pointerLockStateActive
+)
@@ -0,0 +1,165 @@
+//go:build js && wasm
+
+package app
+
+import (
+	"math"
+	"syscall/js"
+)
+
+func newCanvasResizer(htmlCanvas js.Value, maxPixelRatio float64) *canvasResizer {
+	return &canvasResizer{
+		htmlCanvas:    htmlCanvas,
+		maxPixelRatio: maxPixelRatio,
+		observer:      js.Null(),
+	}
+}
+
+// canvasResizer keeps the size of the canvas backing store in sync with
+// the CSS size of the canvas, scaled by the device pixel ratio.
+type canvasResizer struct {
+	htmlCanvas    js.Value
+	maxPixelRatio float64
+	observer      js.Value
+	callback      js.Func
+	active        bool
+}
+
+// Start performs an initial resize and begins observing the canvas for
+// size changes. If the browser does not support ResizeObserver, then
+// Poll needs to be called instead.
+func (r *canvasResizer) Start() {
+	if r.active {
+		return
+	}
+	r.active = true
+	r.Poll()
+
+	jsResizeObserver := js.Global().Get("ResizeObserver")
+	if jsResizeObserver.IsUndefined() {
+		logger.Warn("ResizeObserver not supported; falling back to polling")
+		return
+	}
+	r.callback = js.FuncOf(r.onResize)
+	r.observer = jsResizeObserver.New(r.callback)
+	if isDevicePixelContentBoxSupported() {
+		r.observer.Call("observe", r.htmlCanvas, map[string]any{
+			"box": "device-pixel-content-box",
+		})
+	} else {
+		r.observer.Call("observe", r.htmlCanvas)
+	}
+}
+
+// Stop stops observing the canvas.
+func (r *canvasResizer) Stop() {
+	r.active = false
+	if r.observer.IsNull() {
+		return
+	}
+	r.observer.Call("disconnect")
+	r.observer = js.Null()
+	r.callback.Release()
+}
+
+// Active returns whether the resizer has been started.
+func (r *canvasResizer) Active() bool {
+	return r.active
+}
+
+// Poll resizes the canvas based on its current CSS size. It does nothing
+// while a ResizeObserver is active or the resizer is stopped.
+func (r *canvasResizer) Poll() {
+	if !r.active || !r.observer.IsNull() {
+		return
+	}
+	width := r.htmlCanvas.Get("clientWidth").Float()
+	height := r.htmlCanvas.Get("clientHeight").Float()
+	scale := r.pixelRatio()
+	r.resize(width*scale, height*scale)
+}
+
+func (r *canvasResizer) onResize(this js.Value, args []js.Value) any {
+	jsEntries := args[0]
+	if jsEntries.Length() == 0 {
+		return nil
+	}
+	jsEntry := jsEntries.Index(jsEntries.Length() - 1)
+
+	// The device pixel size is exact and avoids rounding errors that
+	// would cause the canvas to be resampled.
+	if jsSize, ok := firstBoxSize(jsEntry.Get("devicePixelContentBoxSize")); ok {
+		devicePixelRatio := jsDevicePixelRatio()
+		scale := r.pixelRatio() / devicePixelRatio
+		r.resize(
+			jsSize.Get("inlineSize").Float()*scale,
+			jsSize.Get("blockSize").Float()*scale,
+		)
+		return nil
+	}
+
+	scale := r.pixelRatio()
+	if jsSize, ok := firstBoxSize(jsEntry.Get("contentBoxSize")); ok {
+		r.resize(
+			jsSize.Get("inlineSize").Float()*scale,
+			jsSize.Get("blockSize").Float()*scale,
+		)
+		return nil
+	}
+	jsRect := jsEntry.Get("contentRect")
+	r.resize(
+		jsRect.Get("width").Float()*scale,
+		jsRect.Get("height").Float()*scale,
+	)
+	return nil
+}
+
+func (r *canvasResizer) pixelRatio() float64 {
+	ratio := jsDevicePixelRatio()
+	if r.maxPixelRatio > 0.0 {
+		ratio = min(ratio, r.maxPixelRatio)
+	}
+	return ratio
+}
+
+func (r *canvasResizer) resize(width, height float64) {
+	newWidth := max(int(math.Round(width)), 1)
+	newHeight := max(int(math.Round(height)), 1)
+	if r.htmlCanvas.Get("width").Int() != newWidth {
+		r.htmlCanvas.Set("width", newWidth)
+	}
+	if r.htmlCanvas.Get("height").Int() != newHeight {
+		r.htmlCanvas.Set("height", newHeight)
+	}
+}
+
+// firstBoxSize returns the first ResizeObserverSize of a box size
+// property. Older browsers report a single object instead of an array.
+func firstBoxSize(jsBoxSize js.Value) (js.Value, bool) {
+	if jsBoxSize.IsUndefined() || jsBoxSize.IsNull() {
+		return js.Null(), false
+	}
+	if jsBoxSize.Get("inlineSize").Type() == js.TypeNumber {
+		return jsBoxSize, true
+	}
+	if jsBoxSize.Length() == 0 {
+		return js.Null(), false
+	}
+	return jsBoxSize.Index(0), true
+}
+
+func isDevicePixelContentBoxSupported() bool {
+	jsEntry := js.Global().Get("ResizeObserverEntry")
+	if jsEntry.IsUndefined() {
+		return false
+	}
+	return jsEntry.Get("prototype").Call("hasOwnProperty", "devicePixelContentBoxSize").Bool()
+}
+
+func jsDevicePixelRatio() float64 {
+	ratio := js.Global().Get("devicePixelRatio")
+	if ratio.Type() != js.TypeNumber || ratio.Float() <= 0.0 {
+		return 1.0
+	}
+	return ratio.Float()
+}
@@ -0,0 +1,201 @@
+package app
+
+import (
+	"errors"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrFileNotFound is reported when a file that does not exist is read
+// from a Storage.
+var ErrFileNotFound = errors.New("file not found")
+
+// ErrStorageUnavailable is reported when the browser does not allow
+// access to persistent storage (e.g. in some private browsing modes).
+var ErrStorageUnavailable = errors.New("storage unavailable")
+
+// StorageEstimate holds information about the amount of storage that is
+// used by the application.
+type StorageEstimate struct {
+
+	// Usage is the number of bytes that are in use.
+	Usage int64
+
+	// Quota is the number of bytes that the application is allowed to use.
+	// It is zero if the quota is unknown.
+	Quota int64
+}
+
+// Available returns the number of bytes that can still be stored, or zero
+// if the quota is unknown.
+func (e StorageEstimate) Available() int64 {
+	return max(e.Quota-e.Usage, 0)
+}
+
+// Storage provides persistent storage for save games and settings.
+//
+// Values are small strings (e.g. settings) that are stored and retrieved
+// synchronously. Files hold arbitrary data (e.g. save games) and are
+// accessed asynchronously, with callbacks delivered through the task
+// queue of the window. File paths use forward slashes and are relative
+// to the root of the storage; a leading slash is ignored.
+type Storage interface {
+
+	// Value returns the value stored under the specified key and whether
+	// there is one.
+	Value(key string) (string, bool)
+
+	// SetValue stores a value under the specified key.
+	SetValue(key, value string) error
+
+	// DeleteValue removes the value stored under the specified key.
+	DeleteValue(key string) error
+
+	// ReadFile reads the contents of the file at the specified path. The
+	// callback receives ErrFileNotFound if there is no such file.
+	ReadFile(path string, callback func(data []byte, err error))
+
+	// WriteFile creates or replaces the file at the specified path. The
+	// callback can be nil.
+	WriteFile(path string, data []byte, callback func(err error))
+
+	// DeleteFile removes the file at the specified path, if there is one.
+	// The callback can be nil.
+	DeleteFile(path string, callback func(err error))
+
+	// ListFiles returns the sorted paths of all files within the specified
+	// directory and its subdirectories.
+	ListFiles(dir string, callback func(paths []string, err error))
+
+	// Estimate returns the storage usage and quota of the application.
+	Estimate(callback func(estimate StorageEstimate, err error))
+}
+
+// NewMemoryStorage creates a Storage that keeps its data in memory. It can
+// be used in place of browser storage outside of the browser (e.g. in
+// tests), since it does not depend on syscall/js. Callbacks
+// are passed to schedule (e.g. app.Window.Schedule) or are called
+// directly if schedule is nil.
+func NewMemoryStorage(schedule func(fn func())) Storage {
+	if schedule == nil {
+		schedule = func(fn func()) { fn() }
+	}
+	return &memoryStorage{
+		schedule: schedule,
+		values:   make(map[string]string),
+		files:    make(map[string][]byte),
+	}
+}
+
+type memoryStorage struct {
+	schedule func(fn func())
+	mu       sync.Mutex
+	values   map[string]string
+	files    map[string][]byte
+}
+
+func (s *memoryStorage) Value(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+func (s *memoryStorage) SetValue(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+	return nil
+}
+
+func (s *memoryStorage) DeleteValue(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	return nil
+}
+
+func (s *memoryStorage) ReadFile(filePath string, callback func(data []byte, err error)) {
+	s.mu.Lock()
+	data, ok := s.files[storagePath(filePath)]
+	s.mu.Unlock()
+	s.schedule(func() {
+		if !ok {
+			callback(nil, ErrFileNotFound)
+		} else {
+			callback(slices.Clone(data), nil)
+		}
+	})
+}
+
+func (s *memoryStorage) WriteFile(filePath string, data []byte, callback func(err error)) {
+	s.mu.Lock()
+	s.files[storagePath(filePath)] = slices.Clone(data)
+	s.mu.Unlock()
+	if callback != nil {
+		s.schedule(func() {
+			callback(nil)
+		})
+	}
+}
+
+func (s *memoryStorage) DeleteFile(filePath string, callback func(err error)) {
+	s.mu.Lock()
+	delete(s.files, storagePath(filePath))
+	s.mu.Unlock()
+	if callback != nil {
+		s.schedule(func() {
+			callback(nil)
+		})
+	}
+}
+
+func (s *memoryStorage) ListFiles(dir string, callback func(paths []string, err error)) {
+	prefix := storageDirPrefix(dir)
+	s.mu.Lock()
+	var paths []string
+	for filePath := range maps.Keys(s.files) {
+		if strings.HasPrefix(filePath, prefix) {
+			paths = append(paths, filePath)
+		}
+	}
+	s.mu.Unlock()
+	slices.Sort(paths)
+	s.schedule(func() {
+		callback(paths, nil)
+	})
+}
+
+func (s *memoryStorage) Estimate(callback func(estimate StorageEstimate, err error)) {
+	s.mu.Lock()
+	var usage int64
+	for _, data := range s.files {
+		usage += int64(len(data))
+	}
+	for key, value := range s.values {
+		usage += int64(len(key) + len(value))
+	}
+	s.mu.Unlock()
+	s.schedule(func() {
+		callback(StorageEstimate{Usage: usage}, nil)
+	})
+}
+
+// storagePath normalizes a file path so that equivalent paths refer to
+// the same file.
+func storagePath(filePath string) string {
+	return strings.TrimPrefix(path.Clean("/"+filePath), "/")
+}
+
+// storageDirPrefix returns the prefix that is shared by the paths of all
+// files within the specified directory.
+func storageDirPrefix(dir string) string {
+	prefix := storagePath(dir)
+	if prefix == "" {
+		return ""
+	}
+	return prefix + "/"
+}
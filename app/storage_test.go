@@ -0,0 +1,127 @@
+package app_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/mokiat/lacking-js/app"
+)
+
+func TestMemoryStorageValues(t *testing.T) {
+	storage := app.NewMemoryStorage(nil)
+
+	if _, ok := storage.Value("volume"); ok {
+		t.Errorf("expected no value")
+	}
+	if err := storage.SetValue("volume", "0.5"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value, ok := storage.Value("volume"); !ok || value != "0.5" {
+		t.Errorf("expected value %q, got %q (%t)", "0.5", value, ok)
+	}
+	if err := storage.DeleteValue("volume"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := storage.Value("volume"); ok {
+		t.Errorf("expected value to be deleted")
+	}
+}
+
+func TestMemoryStorageFiles(t *testing.T) {
+	storage := app.NewMemoryStorage(nil)
+
+	data := []byte("save game")
+	storage.WriteFile("/saves/slot1.dat", data, func(err error) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	data[0] = 'X' // the storage should have kept a copy
+
+	storage.ReadFile("saves//slot1.dat", func(result []byte, err error) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(result) != "save game" {
+			t.Errorf("expected %q, got %q", "save game", result)
+		}
+	})
+
+	storage.DeleteFile("saves/slot1.dat", nil)
+	storage.ReadFile("saves/slot1.dat", func(result []byte, err error) {
+		if !errors.Is(err, app.ErrFileNotFound) {
+			t.Errorf("expected ErrFileNotFound, got %v", err)
+		}
+	})
+}
+
+func TestMemoryStorageListFiles(t *testing.T) {
+	storage := app.NewMemoryStorage(nil)
+	for _, filePath := range []string{"saves/b.dat", "saves/a.dat", "saves/auto/c.dat", "savesx/d.dat", "settings.json"} {
+		storage.WriteFile(filePath, []byte{1}, nil)
+	}
+
+	testCases := []struct {
+		dir      string
+		expected []string
+	}{
+		{dir: "", expected: []string{"saves/a.dat", "saves/auto/c.dat", "saves/b.dat", "savesx/d.dat", "settings.json"}},
+		{dir: "/", expected: []string{"saves/a.dat", "saves/auto/c.dat", "saves/b.dat", "savesx/d.dat", "settings.json"}},
+		{dir: "saves", expected: []string{"saves/a.dat", "saves/auto/c.dat", "saves/b.dat"}},
+		{dir: "/saves/auto/", expected: []string{"saves/auto/c.dat"}},
+		{dir: "missing", expected: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.dir, func(t *testing.T) {
+			storage.ListFiles(tc.dir, func(paths []string, err error) {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !slices.Equal(paths, tc.expected) {
+					t.Errorf("expected %v, got %v", tc.expected, paths)
+				}
+			})
+		})
+	}
+}
+
+func TestMemoryStorageSchedule(t *testing.T) {
+	var scheduled []func()
+	storage := app.NewMemoryStorage(func(fn func()) {
+		scheduled = append(scheduled, fn)
+	})
+
+	var called bool
+	storage.WriteFile("file", []byte("data"), func(err error) {
+		called = true
+	})
+	if called {
+		t.Fatalf("expected callback to be scheduled instead of called")
+	}
+	if len(scheduled) != 1 {
+		t.Fatalf("expected 1 scheduled callback, got %d", len(scheduled))
+	}
+	scheduled[0]()
+	if !called {
+		t.Errorf("expected callback to be called")
+	}
+}
+
+func TestMemoryStorageEstimate(t *testing.T) {
+	storage := app.NewMemoryStorage(nil)
+	storage.SetValue("key", "value")
+	storage.WriteFile("file", make([]byte, 100), nil)
+
+	storage.Estimate(func(estimate app.StorageEstimate, err error) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if estimate.Usage != 108 {
+			t.Errorf("expected usage 108, got %d", estimate.Usage)
+		}
+		if estimate.Available() != 0 {
+			t.Errorf("expected no available bytes for unknown quota, got %d", estimate.Available())
+		}
+	})
+}
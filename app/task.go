@@ -0,0 +1,192 @@
+package app
+
+import (
+	"sync"
+	"time"
+)
+
+// TaskPriority specifies the order in which scheduled tasks are
+// processed.
+type TaskPriority int
+
+const (
+	// TaskPriorityCritical tasks are all processed on the next frame,
+	// regardless of the time budget for tasks.
+	TaskPriorityCritical TaskPriority = iota
+
+	// TaskPriorityNormal tasks are processed within the time budget of
+	// each frame. This is the priority of tasks that are scheduled through
+	// app.Window.Schedule.
+	TaskPriorityNormal
+
+	// TaskPriorityBackground tasks are processed within the time budget
+	// of each frame, once there are no normal priority tasks left.
+	TaskPriorityBackground
+
+	taskPriorityCount
+)
+
+const (
+	// minTaskBudget is the minimum amount of time that is spent on tasks
+	// per frame, so that progress is made even when rendering is slow.
+	minTaskBudget = 2 * time.Millisecond
+
+	// maxTaskBudget is the maximum amount of time that is spent on tasks
+	// per frame.
+	maxTaskBudget = 30 * time.Millisecond
+
+	// taskBudgetReserve is the amount of frame time that is left unused,
+	// to account for browser work that is not part of the loop.
+	taskBudgetReserve = 2 * time.Millisecond
+)
+
+// TaskQueueStats holds information about the scheduled tasks.
+type TaskQueueStats struct {
+
+	// Pending is the number of tasks that are waiting to be processed,
+	// indexed by TaskPriority.
+	Pending [taskPriorityCount]int
+
+	// PeakPending is the largest total number of pending tasks that has
+	// been observed.
+	PeakPending int
+
+	// Processed is the number of tasks that were processed during the
+	// last frame.
+	Processed int
+
+	// Budget is the amount of time that was available for processing
+	// normal and background tasks during the last frame.
+	Budget time.Duration
+}
+
+func newTaskQueue(limit int) *taskQueue {
+	return &taskQueue{
+		limit: limit,
+	}
+}
+
+// taskQueue is a FIFO queue of tasks per priority. Tasks can be pushed
+// from any goroutine.
+type taskQueue struct {
+	mu        sync.Mutex
+	tasks     [taskPriorityCount][]func()
+	heads     [taskPriorityCount]int
+	limit     int
+	size      int
+	peakSize  int
+	processed int
+	budget    time.Duration
+}
+
+// Push adds a task with the specified priority to the queue. It returns
+// false if the priority is not valid or the queue has reached its limit.
+func (q *taskQueue) Push(fn func(), priority TaskPriority) bool {
+	if priority < 0 || priority >= taskPriorityCount {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.limit > 0 && q.size >= q.limit {
+		return false
+	}
+	q.tasks[priority] = append(q.tasks[priority], fn)
+	q.size++
+	q.peakSize = max(q.peakSize, q.size)
+	return true
+}
+
+// Pop removes the oldest task with the specified priority from the queue.
+func (q *taskQueue) Pop(priority TaskPriority) (func(), bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	tasks := q.tasks[priority]
+	head := q.heads[priority]
+	if head >= len(tasks) {
+		return nil, false
+	}
+	fn := tasks[head]
+	tasks[head] = nil
+	head++
+	if head == len(tasks) {
+		// Reuse the backing array once it has been drained.
+		q.tasks[priority] = tasks[:0]
+		head = 0
+	} else if head > 1024 && head > len(tasks)/2 {
+		// Reclaim the space of processed tasks during long bursts.
+		q.tasks[priority] = append(tasks[:0], tasks[head:]...)
+		clear(tasks[len(tasks)-head:])
+		head = 0
+	}
+	q.heads[priority] = head
+	q.size--
+	return fn, true
+}
+
+// Process runs tasks in order of priority. Critical tasks that were queued
+// before the call are all run, while the rest are run until the specified
+// time budget is exhausted. Critical tasks that are queued by other tasks
+// are left for the next call. It returns whether all tasks were processed.
+func (q *taskQueue) Process(budget time.Duration) bool {
+	startTime := time.Now()
+	processed := 0
+	defer func() {
+		q.mu.Lock()
+		q.processed = processed
+		q.budget = budget
+		q.mu.Unlock()
+	}()
+
+	critical := q.Stats().Pending[TaskPriorityCritical]
+	for range critical {
+		fn, ok := q.Pop(TaskPriorityCritical)
+		if !ok {
+			break
+		}
+		fn()
+		processed++
+	}
+	for _, priority := range []TaskPriority{TaskPriorityNormal, TaskPriorityBackground} {
+		for time.Since(startTime) < budget {
+			fn, ok := q.Pop(priority)
+			if !ok {
+				break
+			}
+			fn()
+			processed++
+		}
+	}
+	return q.Stats().pendingTotal() == 0
+}
+
+// Stats returns information about the state of the queue.
+func (q *taskQueue) Stats() TaskQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var stats TaskQueueStats
+	for priority := range taskPriorityCount {
+		stats.Pending[priority] = len(q.tasks[priority]) - q.heads[priority]
+	}
+	stats.PeakPending = q.peakSize
+	stats.Processed = q.processed
+	stats.Budget = q.budget
+	return stats
+}
+
+func (s TaskQueueStats) pendingTotal() int {
+	var total int
+	for _, count := range s.Pending {
+		total += count
+	}
+	return total
+}
+
+// taskBudget determines how much time can be spent on tasks during a
+// frame, based on the measured frame interval and rendering duration.
+func taskBudget(frameInterval, renderDuration time.Duration) time.Duration {
+	if frameInterval <= 0 {
+		return maxTaskBudget
+	}
+	budget := frameInterval - renderDuration - taskBudgetReserve
+	return min(max(budget, minTaskBudget), maxTaskBudget)
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTaskQueueOrder(t *testing.T) {
+	queue := newTaskQueue(0)
+	var order []string
+	push := func(name string, priority TaskPriority) {
+		if !queue.Push(func() { order = append(order, name) }, priority) {
+			t.Fatalf("task %q rejected", name)
+		}
+	}
+	push("background-1", TaskPriorityBackground)
+	push("normal-1", TaskPriorityNormal)
+	push("critical-1", TaskPriorityCritical)
+	push("normal-2", TaskPriorityNormal)
+	push("critical-2", TaskPriorityCritical)
+
+	if done := queue.Process(time.Hour); !done {
+		t.Errorf("expected all tasks to be processed")
+	}
+	expected := []string{"critical-1", "critical-2", "normal-1", "normal-2", "background-1"}
+	if !slices.Equal(order, expected) {
+		t.Errorf("expected order %v, got %v", expected, order)
+	}
+	if stats := queue.Stats(); stats.Processed != 5 || stats.PeakPending != 5 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestTaskQueueLimit(t *testing.T) {
+	queue := newTaskQueue(2)
+	if !queue.Push(func() {}, TaskPriorityNormal) || !queue.Push(func() {}, TaskPriorityCritical) {
+		t.Fatalf("expected tasks within the limit to be accepted")
+	}
+	if queue.Push(func() {}, TaskPriorityBackground) {
+		t.Errorf("expected task beyond the limit to be rejected")
+	}
+	queue.Process(time.Hour)
+	if !queue.Push(func() {}, TaskPriorityNormal) {
+		t.Errorf("expected task to be accepted once the queue is drained")
+	}
+}
+
+func TestTaskQueueInvalidPriority(t *testing.T) {
+	queue := newTaskQueue(0)
+	for _, priority := range []TaskPriority{-1, taskPriorityCount, 100} {
+		if queue.Push(func() {}, priority) {
+			t.Errorf("expected priority %d to be rejected", priority)
+		}
+	}
+	if pending := queue.Stats().pendingTotal(); pending != 0 {
+		t.Errorf("expected no pending tasks, got %d", pending)
+	}
+}
+
+func TestTaskQueueRecursiveCritical(t *testing.T) {
+	queue := newTaskQueue(0)
+	var count int
+	var task func()
+	task = func() {
+		count++
+		queue.Push(task, TaskPriorityCritical)
+	}
+	queue.Push(task, TaskPriorityCritical)
+
+	if done := queue.Process(time.Hour); done {
+		t.Errorf("expected the rescheduled task to remain pending")
+	}
+	if count != 1 {
+		t.Errorf("expected 1 run per call, got %d", count)
+	}
+	queue.Process(time.Hour)
+	if count != 2 {
+		t.Errorf("expected 2 runs after two calls, got %d", count)
+	}
+}
+
+func TestTaskQueueBudget(t *testing.T) {
+	queue := newTaskQueue(0)
+	var count int
+	for range 3 {
+		queue.Push(func() {
+			count++
+			time.Sleep(5 * time.Millisecond)
+		}, TaskPriorityNormal)
+	}
+	queue.Push(func() { count++ }, TaskPriorityCritical)
+
+	if done := queue.Process(0); done {
+		t.Errorf("expected normal tasks to remain pending")
+	}
+	if count != 1 {
+		t.Errorf("expected only the critical task to run, got %d tasks", count)
+	}
+	if pending := queue.Stats().Pending[TaskPriorityNormal]; pending != 3 {
+		t.Errorf("expected 3 pending normal tasks, got %d", pending)
+	}
+}
+
+func TestTaskQueueLongBurst(t *testing.T) {
+	queue := newTaskQueue(0)
+	const count = 5000
+	var processed int
+	for range count {
+		queue.Push(func() { processed++ }, TaskPriorityBackground)
+	}
+	for processed < count/2 {
+		fn, ok := queue.Pop(TaskPriorityBackground)
+		if !ok {
+			t.Fatalf("unexpected empty queue")
+		}
+		fn()
+	}
+	queue.Push(func() { processed++ }, TaskPriorityBackground)
+	queue.Process(time.Hour)
+	if processed != count+1 {
+		t.Errorf("expected %d processed tasks, got %d", count+1, processed)
+	}
+	if pending := queue.Stats().pendingTotal(); pending != 0 {
+		t.Errorf("expected no pending tasks, got %d", pending)
+	}
+}
+
+func TestTaskBudget(t *testing.T) {
+	testCases := []struct {
+		name           string
+		frameInterval  time.Duration
+		renderDuration time.Duration
+		expected       time.Duration
+	}{
+		{name: "unknown interval", frameInterval: 0, expected: maxTaskBudget},
+		{name: "idle frame", frameInterval: 16 * time.Millisecond, renderDuration: 4 * time.Millisecond, expected: 10 * time.Millisecond},
+		{name: "slow frame", frameInterval: 16 * time.Millisecond, renderDuration: 20 * time.Millisecond, expected: minTaskBudget},
+		{name: "long interval", frameInterval: time.Second, expected: maxTaskBudget},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if budget := taskBudget(tc.frameInterval, tc.renderDuration); budget != tc.expected {
+				t.Errorf("expected budget %v, got %v", tc.expected, budget)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+//go:build js && wasm
+
+package app
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/mokiat/lacking/app"
+)
+
+// TextCompositionAction represents the stage of an IME text composition.
+type TextCompositionAction int
+
+const (
+	// TextCompositionActionStart indicates that the user has started
+	// composing text (e.g. started typing Japanese with an IME).
+	TextCompositionActionStart TextCompositionAction = iota
+
+	// TextCompositionActionUpdate indicates that the preedit text has
+	// changed.
+	TextCompositionActionUpdate
+
+	// TextCompositionActionEnd indicates that the composition has completed
+	// or was cancelled. Committed text is delivered separately through
+	// app.KeyboardActionType events.
+	TextCompositionActionEnd
+)
+
+// TextCompositionEvent describes a change to an IME text composition.
+type TextCompositionEvent struct {
+
+	// Action specifies the stage of the composition.
+	Action TextCompositionAction
+
+	// Text is the preedit text that is being composed. It should be
+	// displayed by the application (usually underlined) at the text cursor
+	// but it is not yet part of the edited text.
+	Text string
+}
+
+func newTextInput(htmlDocument, htmlCanvas js.Value) *textInput {
+	htmlInput := htmlDocument.Call("createElement", "textarea")
+	htmlInput.Call("setAttribute", "autocomplete", "off")
+	htmlInput.Call("setAttribute", "autocorrect", "off")
+	htmlInput.Call("setAttribute", "autocapitalize", "off")
+	htmlInput.Call("setAttribute", "spellcheck", "false")
+	htmlInput.Call("setAttribute", "aria-hidden", "true")
+
+	// NOTE: The element should not be visible but it cannot be hidden
+	// through display or visibility, since browsers would not focus it and
+	// IMEs need a position at which to show the candidate window.
+	htmlStyle := htmlInput.Get("style")
+	htmlStyle.Set("position", "fixed")
+	htmlStyle.Set("left", "0px")
+	htmlStyle.Set("top", "0px")
+	htmlStyle.Set("width", "1px")
+	htmlStyle.Set("height", "1px")
+	htmlStyle.Set("padding", "0px")
+	htmlStyle.Set("border", "none")
+	htmlStyle.Set("outline", "none")
+	htmlStyle.Set("resize", "none")
+	htmlStyle.Set("overflow", "hidden")
+	htmlStyle.Set("opacity", "0")
+	htmlStyle.Set("pointerEvents", "none")
+	htmlStyle.Set("zIndex", "-1")
+
+	return &textInput{
+		htmlDocument: htmlDocument,
+		htmlCanvas:   htmlCanvas,
+		htmlInput:    htmlInput,
+	}
+}
+
+// textInput manages a hidden textarea element that receives keyboard focus
+// while text input is enabled, so that the browser performs layout-aware
+// character translation, dead key handling and IME composition.
+type textInput struct {
+	htmlDocument js.Value
+	htmlCanvas   js.Value
+	htmlInput    js.Value
+	enabled      bool
+}
+
+// Attach inserts the textarea element into the document.
+func (t *textInput) Attach() {
+	t.htmlDocument.Get("body").Call("appendChild", t.htmlInput)
+}
+
+// Detach removes the textarea element from the document.
+func (t *textInput) Detach() {
+	t.htmlInput.Call("remove")
+}
+
+// Enabled returns whether text input is enabled.
+func (t *textInput) Enabled() bool {
+	return t.enabled
+}
+
+// SetEnabled moves keyboard focus to the textarea element or back to
+// the canvas.
+func (t *textInput) SetEnabled(enabled bool) {
+	if enabled == t.enabled {
+		return
+	}
+	t.enabled = enabled
+	t.htmlInput.Set("value", "")
+	if enabled {
+		t.Focus()
+	} else {
+		t.htmlInput.Call("blur")
+		t.htmlCanvas.Call("focus")
+	}
+}
+
+// Focus moves keyboard focus to the textarea element, if text input is
+// enabled.
+func (t *textInput) Focus() {
+	if t.enabled {
+		t.htmlInput.Call("focus", map[string]any{
+			"preventScroll": true,
+		})
+	}
+}
+
+// SetPosition places the textarea element at the specified canvas
+// position, which is where browsers show the IME candidate window.
+func (t *textInput) SetPosition(x, y int) {
+	jsRect := t.htmlCanvas.Call("getBoundingClientRect")
+	left := jsRect.Get("left").Float() + float64(x)
+	top := jsRect.Get("top").Float() + float64(y)
+	htmlStyle := t.htmlInput.Get("style")
+	htmlStyle.Set("left", fmt.Sprintf("%fpx", left))
+	htmlStyle.Set("top", fmt.Sprintf("%fpx", top))
+}
+
+// Clear removes any text that has accumulated in the textarea element.
+func (t *textInput) Clear() {
+	t.htmlInput.Set("value", "")
+}
+
+func (l *loop) TextInputEnabled() bool {
+	return l.textInput.Enabled()
+}
+
+func (l *loop) SetTextInputEnabled(enabled bool) {
+	l.textInput.SetEnabled(enabled)
+}
+
+func (l *loop) SetTextInputPosition(x, y int) {
+	l.textInput.SetPosition(x, y)
+}
+
+func (l *loop) onJSTextInput(this js.Value, args []js.Value) any {
+	event := args[0]
+	if event.Get("isComposing").Bool() {
+		// The text is delivered once the composition ends.
+		return nil
+	}
+	switch event.Get("inputType").String() {
+	case "insertText", "insertReplacementText", "insertFromPaste", "insertFromDrop":
+		jsData := event.Get("data")
+		if jsData.Type() == js.TypeString {
+			l.typeText(jsData.String())
+		} else {
+			// Paste and drop do not populate data, so the text is taken from
+			// the element instead.
+			l.typeText(l.textInput.htmlInput.Get("value").String())
+		}
+	}
+	l.textInput.Clear()
+	return nil
+}
+
+func (l *loop) onJSCompositionStart(this js.Value, args []js.Value) any {
+	l.dispatchTextComposition(TextCompositionEvent{
+		Action: TextCompositionActionStart,
+	})
+	return nil
+}
+
+func (l *loop) onJSCompositionUpdate(this js.Value, args []js.Value) any {
+	event := args[0]
+	l.dispatchTextComposition(TextCompositionEvent{
+		Action: TextCompositionActionUpdate,
+		Text:   event.Get("data").String(),
+	})
+	return nil
+}
+
+func (l *loop) onJSCompositionEnd(this js.Value, args []js.Value) any {
+	event := args[0]
+	l.dispatchTextComposition(TextCompositionEvent{
+		Action: TextCompositionActionEnd,
+	})
+	l.typeText(event.Get("data").String())
+	l.textInput.Clear()
+	return nil
+}
+
+func (l *loop) dispatchTextComposition(event TextCompositionEvent) {
+	if controller, ok := l.controller.(TextCompositionController); ok {
+		controller.OnTextComposition(l, event)
+	}
+}
+
+// typeText delivers committed text to the controller as a sequence of
+// app.KeyboardActionType events.
+func (l *loop) typeText(text string) {
+	for _, ch := range text {
+		l.controller.OnKeyboardEvent(l, app.KeyboardEvent{
+			Action:    app.KeyboardActionType,
+			Character: ch,
+		})
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"log/slog"
 	"syscall/js"
 
+	jsrender "github.com/mokiat/lacking-js/render"
 	"github.com/mokiat/lacking/app"
 	_ "github.com/mokiat/lacking/debug/log"
+	"github.com/mokiat/lacking/render"
 	"github.com/mokiat/wasmgl"
 )
 
@@ -29,17 +31,28 @@ func Run(cfg *Config, controller app.Controller) error {
 		return fmt.Errorf("could not locate canvas element")
 	}
 
+	// Touch gestures should produce pointer events instead of scrolling
+	// or zooming the page.
+	htmlCanvas.Get("style").Set("touchAction", "none")
+
 	if cfg.title != nil {
 		htmlDocument.Set("title", *cfg.title)
 	}
 
-	if cfg.fullscreen {
-		htmlBody := htmlDocument.Get("body")
-		bodyWidth := htmlBody.Get("clientWidth").Int()
-		bodyHeight := htmlBody.Get("clientHeight").Int()
-		htmlCanvas.Set("width", bodyWidth)
-		htmlCanvas.Set("height", bodyHeight)
-	} else {
+	htmlStyle := htmlCanvas.Get("style")
+	switch {
+	case cfg.fullscreen:
+		htmlStyle.Set("display", "block")
+		htmlStyle.Set("width", "100vw")
+		htmlStyle.Set("height", "100vh")
+	case cfg.autoResize:
+		if cfg.width != nil {
+			htmlStyle.Set("width", fmt.Sprintf("%dpx", *cfg.width))
+		}
+		if cfg.height != nil {
+			htmlStyle.Set("height", fmt.Sprintf("%dpx", *cfg.height))
+		}
+	default:
 		if cfg.width != nil {
 			htmlCanvas.Set("width", *cfg.width)
 		}
@@ -48,22 +61,12 @@ func Run(cfg *Config, controller app.Controller) error {
 		}
 	}
 
-	// TODO: Make graphics library configurable
-	err := wasmgl.InitFromCanvas(htmlCanvas,
-		wasmgl.WithOptionPowerPreference(wasmgl.PowerPreferenceHighPerformance),
-	)
+	renderAPI, err := newRenderAPI(cfg, htmlCanvas)
 	if err != nil {
-		return fmt.Errorf("error initializing webgl: %w", err)
-	}
-	for _, ext := range cfg.glExtensions {
-		if wasmgl.GetExtension(ext) == nil {
-			logger.Warn("Extension might not be supported",
-				slog.String("extension", ext),
-			)
-		}
+		return fmt.Errorf("error initializing graphics: %w", err)
 	}
 
-	l := newLoop(htmlDocument, htmlCanvas, controller)
+	l := newLoop(cfg, htmlDocument, htmlCanvas, renderAPI, controller)
 	if cfg.cursor != nil {
 		cursor := l.CreateCursor(*cfg.cursor)
 		defer cursor.Destroy()
@@ -75,3 +78,36 @@ func Run(cfg *Config, controller app.Controller) error {
 	}
 	return l.Run(cfg.audioEnabled)
 }
+
+// newRenderAPI creates the WebGL2 render API. It is the only graphics
+// backend, since lacking programs are delivered as GLSL source, which
+// WebGPU cannot consume.
+func newRenderAPI(cfg *Config, htmlCanvas js.Value) (render.API, error) {
+	err := wasmgl.InitFromCanvas(htmlCanvas,
+		wasmgl.WithOptionPowerPreference(wasmgl.PowerPreferenceHighPerformance),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing webgl: %w", err)
+	}
+	enableGLExtensions(cfg.glExtensions)
+	api := jsrender.NewAPI()
+	if jsAPI, ok := api.(*jsrender.API); ok {
+		// NOTE: Requesting the context again returns the one that wasmgl
+		// was initialized with.
+		jsAPI.SetContext(htmlCanvas.Call("getContext", "webgl2"))
+		jsAPI.SetRetainResources(cfg.restoreContext)
+	}
+	return api, nil
+}
+
+// enableGLExtensions enables the specified WebGL extensions. This needs
+// to be repeated when the WebGL context is restored after being lost.
+func enableGLExtensions(extensions []string) {
+	for _, ext := range extensions {
+		if wasmgl.GetExtension(ext) == nil {
+			logger.Warn("Extension might not be supported",
+				slog.String("extension", ext),
+			)
+		}
+	}
+}
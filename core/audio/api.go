@@ -1,6 +1,8 @@
 package audio
 
 import (
+	"syscall/js"
+
 	"github.com/mokiat/lacking-js/core/audio/internal"
 	"github.com/mokiat/lacking/core/audio"
 	"github.com/mokiat/wasmal"
@@ -11,6 +13,9 @@ type API struct {
 
 	masterBus *internal.MasterBus
 	listener  *internal.SpatialListener
+
+	buses     []*internal.Bus
+	suspended bool
 }
 
 var _ audio.API = (*API)(nil)
@@ -35,6 +40,9 @@ func (a *API) CreateMedia(data audio.MediaData) audio.Media {
 func (a *API) CreateBus(settings audio.BusSettings) audio.Bus {
 	bus := internal.NewBus(a.ctx, settings)
 	bus.Output().ConnectToNode(a.masterBus.Input())
+	a.pruneBuses()
+	bus.SetSuspended(a.suspended)
+	a.buses = append(a.buses, bus)
 	return bus
 }
 
@@ -78,6 +86,51 @@ func (a *API) SpatialListener() audio.SpatialListener {
 	return a.listener
 }
 
+// Suspend pauses all buses and suspends the audio context. It is meant to
+// be used while the application is not visible.
+func (a *API) Suspend() {
+	if a.suspended {
+		return
+	}
+	a.suspended = true
+	a.pruneBuses()
+	for _, bus := range a.buses {
+		// Suspension is tracked separately by each bus, so that buses that
+		// are paused by the application remain paused once the API is
+		// resumed.
+		bus.SetSuspended(true)
+	}
+	// NOTE: Pausing the buses is sufficient to silence the application.
+	// Suspending the context additionally releases audio hardware. The
+	// wasmal context does not expose this, so the JavaScript one is used.
+	js.Value(a.ctx).Call("suspend")
+}
+
+// Resume resumes the audio context and all buses that were not paused by
+// the application.
+func (a *API) Resume() {
+	if !a.suspended {
+		return
+	}
+	a.suspended = false
+	js.Value(a.ctx).Call("resume")
+	a.pruneBuses()
+	for _, bus := range a.buses {
+		bus.SetSuspended(false)
+	}
+}
+
+func (a *API) pruneBuses() {
+	liveBuses := a.buses[:0]
+	for _, bus := range a.buses {
+		if !bus.IsReleased() {
+			liveBuses = append(liveBuses, bus)
+		}
+	}
+	clear(a.buses[len(liveBuses):])
+	a.buses = liveBuses
+}
+
 func (p *API) Release() {
 	p.ctx.Close()
 }
@@ -11,8 +11,9 @@ type Bus struct {
 	compressionFilter *CompressionFilter
 	reverbFilter      *ReverbFilter
 
-	playbacks *ds.List[PlaybackNode]
-	isPaused  bool
+	playbacks   *ds.List[PlaybackNode]
+	isPaused    bool
+	isSuspended bool
 }
 
 var _ audio.Bus = (*Bus)(nil)
@@ -60,7 +61,7 @@ func (b *Bus) Output() wasmal.AudioNode {
 }
 
 func (b *Bus) AddPlayback(p PlaybackNode) {
-	if b.isPaused {
+	if b.isPaused || b.isSuspended {
 		p.InternalPause()
 	}
 	b.playbacks.Add(p)
@@ -94,13 +95,23 @@ func (b *Bus) Reverb() audio.Reverb {
 	return b.reverbFilter
 }
 
+// IsPaused returns whether the bus has been paused.
+func (b *Bus) IsPaused() bool {
+	return b.isPaused
+}
+
+// IsReleased returns whether the bus has been released.
+func (b *Bus) IsReleased() bool {
+	return b.playbacks == nil
+}
+
 func (b *Bus) Pause() {
 	if b.isPaused {
 		return
 	}
 	b.isPaused = true
-	for _, p := range b.playbacks.Unbox() {
-		p.InternalPause()
+	if !b.isSuspended {
+		b.pausePlaybacks()
 	}
 }
 
@@ -109,8 +120,26 @@ func (b *Bus) Resume() {
 		return
 	}
 	b.isPaused = false
-	for _, p := range b.playbacks.Unbox() {
-		p.InternalResume()
+	if !b.isSuspended {
+		b.resumePlaybacks()
+	}
+}
+
+// SetSuspended specifies whether the bus is suspended. A suspended bus keeps
+// its playbacks paused, regardless of whether the bus has been paused, and
+// does not affect the pause state reported by IsPaused.
+func (b *Bus) SetSuspended(suspended bool) {
+	if b.isSuspended == suspended {
+		return
+	}
+	b.isSuspended = suspended
+	if b.isPaused {
+		return
+	}
+	if suspended {
+		b.pausePlaybacks()
+	} else {
+		b.resumePlaybacks()
 	}
 }
 
@@ -122,3 +151,15 @@ func (b *Bus) Release() {
 	b.Output().Disconnect()
 	b.playbacks = nil
 }
+
+func (b *Bus) pausePlaybacks() {
+	for _, p := range b.playbacks.Unbox() {
+		p.InternalPause()
+	}
+}
+
+func (b *Bus) resumePlaybacks() {
+	for _, p := range b.playbacks.Unbox() {
+		p.InternalResume()
+	}
+}
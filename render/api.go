@@ -1,20 +1,22 @@
 package render
 
 import (
+	"syscall/js"
+
 	"github.com/mokiat/lacking-js/render/internal"
 	"github.com/mokiat/lacking/render"
 )
 
 func NewAPI() render.API {
 	return &API{
-		limits: internal.NewLimits(),
-		queue:  internal.NewQueue(),
+		limits: &Limits{internal.NewLimits()},
+		queue:  &Queue{internal.NewQueue()},
 	}
 }
 
 type API struct {
-	limits *internal.Limits
-	queue  *internal.Queue
+	limits *Limits
+	queue  *Queue
 }
 
 func (a *API) Limits() render.Limits {
@@ -83,6 +85,12 @@ func (a *API) CreatePixelTransferBuffer(info render.BufferInfo) render.Buffer {
 	return internal.NewPixelTransferBuffer(info)
 }
 
+// CreatePixelUnpackBuffer creates a buffer from which texture pixels can
+// be uploaded through Queue.WriteTexture.
+func (a *API) CreatePixelUnpackBuffer(info render.BufferInfo) render.Buffer {
+	return internal.NewPixelUnpackBuffer(info)
+}
+
 func (a *API) CreateUniformBuffer(info render.BufferInfo) render.Buffer {
 	return internal.NewUniformBuffer(info)
 }
@@ -102,3 +110,26 @@ func (a *API) CreateCommandBuffer(info render.CommandBufferInfo) render.CommandB
 func (a *API) Queue() render.Queue {
 	return a.queue
 }
+
+// SetContext specifies the WebGL2 rendering context of the canvas that
+// wasmgl was initialized with. It is needed for functionality that wasmgl
+// does not provide, such as Queue.WriteTexture.
+func (a *API) SetContext(jsContext js.Value) {
+	internal.SetContext(jsContext)
+}
+
+// SetRetainResources specifies whether resources that are created from
+// now on should keep their creation information (including initial data)
+// in memory, so that they can be recreated by Restore after the WebGL
+// context has been lost.
+func (a *API) SetRetainResources(retain bool) {
+	internal.SetRetainResources(retain)
+}
+
+// Restore should be called after the WebGL context has been restored. It
+// recreates all retained resources and resets any cached state.
+func (a *API) Restore() {
+	a.limits.DetectExtensions()
+	internal.RestoreResources()
+	a.queue.Invalidate()
+}
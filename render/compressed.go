@@ -0,0 +1,387 @@
+package render
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mokiat/lacking-js/render/internal"
+	"github.com/mokiat/lacking/render"
+	"github.com/mokiat/wasmgl"
+)
+
+// CompressedFormat represents a GPU texture compression format.
+type CompressedFormat uint8
+
+const (
+	// CompressedFormatBC1 is the S3TC DXT1 format with 1-bit alpha.
+	CompressedFormatBC1 CompressedFormat = iota + 1
+
+	// CompressedFormatBC3 is the S3TC DXT5 format with full alpha.
+	CompressedFormatBC3
+
+	// CompressedFormatBC4 is the RGTC1 single channel format.
+	CompressedFormatBC4
+
+	// CompressedFormatBC5 is the RGTC2 two channel format, commonly used
+	// for normal maps.
+	CompressedFormatBC5
+
+	// CompressedFormatBC7 is the BPTC high quality RGBA format.
+	CompressedFormatBC7
+
+	// CompressedFormatETC2RGB is the ETC2 format without alpha.
+	CompressedFormatETC2RGB
+
+	// CompressedFormatETC2RGBA is the ETC2 format with EAC alpha.
+	CompressedFormatETC2RGBA
+
+	// CompressedFormatEACR11 is the EAC single channel format.
+	CompressedFormatEACR11
+
+	// CompressedFormatEACRG11 is the EAC two channel format.
+	CompressedFormatEACRG11
+
+	// CompressedFormatASTC4x4 is the ASTC format with 4x4 blocks.
+	CompressedFormatASTC4x4
+
+	// CompressedFormatASTC6x6 is the ASTC format with 6x6 blocks.
+	CompressedFormatASTC6x6
+
+	// CompressedFormatASTC8x8 is the ASTC format with 8x8 blocks.
+	CompressedFormatASTC8x8
+)
+
+// compressedFormatInfo describes how a CompressedFormat maps to WebGL.
+type compressedFormatInfo struct {
+	name          string
+	extension     string
+	srgbExtension string
+	glFormat      wasmgl.GLenum
+	glFormatSRGB  wasmgl.GLenum
+	blockWidth    uint32
+	blockHeight   uint32
+	blockSize     uint32
+}
+
+var compressedFormats = map[CompressedFormat]compressedFormatInfo{
+	CompressedFormatBC1: {
+		name:          "BC1",
+		extension:     "WEBGL_compressed_texture_s3tc",
+		srgbExtension: "WEBGL_compressed_texture_s3tc_srgb",
+		glFormat:      0x83F1, // COMPRESSED_RGBA_S3TC_DXT1_EXT
+		glFormatSRGB:  0x8C4D, // COMPRESSED_SRGB_ALPHA_S3TC_DXT1_EXT
+		blockWidth:    4,
+		blockHeight:   4,
+		blockSize:     8,
+	},
+	CompressedFormatBC3: {
+		name:          "BC3",
+		extension:     "WEBGL_compressed_texture_s3tc",
+		srgbExtension: "WEBGL_compressed_texture_s3tc_srgb",
+		glFormat:      0x83F3, // COMPRESSED_RGBA_S3TC_DXT5_EXT
+		glFormatSRGB:  0x8C4F, // COMPRESSED_SRGB_ALPHA_S3TC_DXT5_EXT
+		blockWidth:    4,
+		blockHeight:   4,
+		blockSize:     16,
+	},
+	CompressedFormatBC4: {
+		name:        "BC4",
+		extension:   "EXT_texture_compression_rgtc",
+		glFormat:    0x8DBB, // COMPRESSED_RED_RGTC1_EXT
+		blockWidth:  4,
+		blockHeight: 4,
+		blockSize:   8,
+	},
+	CompressedFormatBC5: {
+		name:        "BC5",
+		extension:   "EXT_texture_compression_rgtc",
+		glFormat:    0x8DBD, // COMPRESSED_RED_GREEN_RGTC2_EXT
+		blockWidth:  4,
+		blockHeight: 4,
+		blockSize:   16,
+	},
+	CompressedFormatBC7: {
+		name:          "BC7",
+		extension:     "EXT_texture_compression_bptc",
+		srgbExtension: "EXT_texture_compression_bptc",
+		glFormat:      0x8E8C, // COMPRESSED_RGBA_BPTC_UNORM_EXT
+		glFormatSRGB:  0x8E8D, // COMPRESSED_SRGB_ALPHA_BPTC_UNORM_EXT
+		blockWidth:    4,
+		blockHeight:   4,
+		blockSize:     16,
+	},
+	CompressedFormatETC2RGB: {
+		name:          "ETC2 RGB",
+		extension:     "WEBGL_compressed_texture_etc",
+		srgbExtension: "WEBGL_compressed_texture_etc",
+		glFormat:      0x9274, // COMPRESSED_RGB8_ETC2
+		glFormatSRGB:  0x9275, // COMPRESSED_SRGB8_ETC2
+		blockWidth:    4,
+		blockHeight:   4,
+		blockSize:     8,
+	},
+	CompressedFormatETC2RGBA: {
+		name:          "ETC2 RGBA",
+		extension:     "WEBGL_compressed_texture_etc",
+		srgbExtension: "WEBGL_compressed_texture_etc",
+		glFormat:      0x9278, // COMPRESSED_RGBA8_ETC2_EAC
+		glFormatSRGB:  0x9279, // COMPRESSED_SRGB8_ALPHA8_ETC2_EAC
+		blockWidth:    4,
+		blockHeight:   4,
+		blockSize:     16,
+	},
+	CompressedFormatEACR11: {
+		name:        "EAC R11",
+		extension:   "WEBGL_compressed_texture_etc",
+		glFormat:    0x9270, // COMPRESSED_R11_EAC
+		blockWidth:  4,
+		blockHeight: 4,
+		blockSize:   8,
+	},
+	CompressedFormatEACRG11: {
+		name:        "EAC RG11",
+		extension:   "WEBGL_compressed_texture_etc",
+		glFormat:    0x9272, // COMPRESSED_RG11_EAC
+		blockWidth:  4,
+		blockHeight: 4,
+		blockSize:   16,
+	},
+	CompressedFormatASTC4x4: {
+		name:          "ASTC 4x4",
+		extension:     "WEBGL_compressed_texture_astc",
+		srgbExtension: "WEBGL_compressed_texture_astc",
+		glFormat:      0x93B0, // COMPRESSED_RGBA_ASTC_4x4_KHR
+		glFormatSRGB:  0x93D0, // COMPRESSED_SRGB8_ALPHA8_ASTC_4x4_KHR
+		blockWidth:    4,
+		blockHeight:   4,
+		blockSize:     16,
+	},
+	CompressedFormatASTC6x6: {
+		name:          "ASTC 6x6",
+		extension:     "WEBGL_compressed_texture_astc",
+		srgbExtension: "WEBGL_compressed_texture_astc",
+		glFormat:      0x93B4, // COMPRESSED_RGBA_ASTC_6x6_KHR
+		glFormatSRGB:  0x93D4, // COMPRESSED_SRGB8_ALPHA8_ASTC_6x6_KHR
+		blockWidth:    6,
+		blockHeight:   6,
+		blockSize:     16,
+	},
+	CompressedFormatASTC8x8: {
+		name:          "ASTC 8x8",
+		extension:     "WEBGL_compressed_texture_astc",
+		srgbExtension: "WEBGL_compressed_texture_astc",
+		glFormat:      0x93B7, // COMPRESSED_RGBA_ASTC_8x8_KHR
+		glFormatSRGB:  0x93D7, // COMPRESSED_SRGB8_ALPHA8_ASTC_8x8_KHR
+		blockWidth:    8,
+		blockHeight:   8,
+		blockSize:     16,
+	},
+}
+
+// compressedFormatPreference lists the formats in the order in which
+// SelectCompressedFormat considers them. Formats with more channels come
+// first, so that an asset that is available in formats with different
+// channel counts does not lose channels. Within the same channel count,
+// formats are ordered from highest to lowest quality.
+var compressedFormatPreference = []CompressedFormat{
+	// RGBA
+	CompressedFormatASTC4x4,
+	CompressedFormatBC7,
+	CompressedFormatETC2RGBA,
+	CompressedFormatBC3,
+	CompressedFormatASTC6x6,
+	CompressedFormatASTC8x8,
+
+	// RGB
+	CompressedFormatETC2RGB,
+	CompressedFormatBC1,
+
+	// RG
+	CompressedFormatBC5,
+	CompressedFormatEACRG11,
+
+	// R
+	CompressedFormatBC4,
+	CompressedFormatEACR11,
+}
+
+// String returns the name of the format.
+func (f CompressedFormat) String() string {
+	if info, ok := compressedFormats[f]; ok {
+		return info.name
+	}
+	return fmt.Sprintf("CompressedFormat(%d)", uint8(f))
+}
+
+// BlockSize returns the dimensions of a block in pixels and the number of
+// bytes that a block occupies.
+func (f CompressedFormat) BlockSize() (width, height, size uint32) {
+	info := compressedFormats[f]
+	return info.blockWidth, info.blockHeight, info.blockSize
+}
+
+// DataSize returns the number of bytes that an image with the specified
+// dimensions occupies in this format.
+func (f CompressedFormat) DataSize(width, height uint32) uint32 {
+	info, ok := compressedFormats[f]
+	if !ok {
+		return 0
+	}
+	blocksX := (width + info.blockWidth - 1) / info.blockWidth
+	blocksY := (height + info.blockHeight - 1) / info.blockHeight
+	return blocksX * blocksY * info.blockSize
+}
+
+// CompressedMipmapLayer holds the data of a single mipmap level of a
+// compressed 2D texture.
+type CompressedMipmapLayer struct {
+	Width  uint32
+	Height uint32
+	Data   []byte
+}
+
+// CompressedTexture2DInfo describes a compressed 2D texture.
+type CompressedTexture2DInfo struct {
+	Label           string
+	Format          CompressedFormat
+	GammaCorrection bool
+
+	// MipmapLayers holds the data of each mipmap level. Compressed
+	// textures cannot have their mipmaps generated, so all required levels
+	// need to be provided.
+	MipmapLayers []CompressedMipmapLayer
+}
+
+// CompressedCubeMipmapLayer holds the data of a single mipmap level of a
+// compressed cube texture.
+type CompressedCubeMipmapLayer struct {
+	Dimension      uint32
+	RightSideData  []byte
+	LeftSideData   []byte
+	BottomSideData []byte
+	TopSideData    []byte
+	FrontSideData  []byte
+	BackSideData   []byte
+}
+
+// CompressedTextureCubeInfo describes a compressed cube texture.
+type CompressedTextureCubeInfo struct {
+	Label           string
+	Format          CompressedFormat
+	GammaCorrection bool
+	MipmapLayers    []CompressedCubeMipmapLayer
+}
+
+// Limits is the render.Limits of the API. It additionally reports the
+// supported compressed texture formats.
+type Limits struct {
+	*internal.Limits
+}
+
+// SupportsCompressedFormat returns whether textures of the specified
+// format can be created. Gamma corrected variants are reported
+// separately, since they can depend on an additional extension.
+func (l *Limits) SupportsCompressedFormat(format CompressedFormat, gammaCorrection bool) bool {
+	info, ok := compressedFormats[format]
+	if !ok {
+		return false
+	}
+	if gammaCorrection {
+		return info.srgbExtension != "" && l.SupportsExtension(info.srgbExtension)
+	}
+	return l.SupportsExtension(info.extension)
+}
+
+// CompressedFormats returns all compressed formats that are supported
+// without gamma correction, in order of preference.
+func (l *Limits) CompressedFormats() []CompressedFormat {
+	var result []CompressedFormat
+	for _, format := range compressedFormatPreference {
+		if l.SupportsCompressedFormat(format, false) {
+			result = append(result, format)
+		}
+	}
+	return result
+}
+
+// SelectCompressedFormat picks the best supported format out of the
+// formats in which an asset is available. It returns false if none of
+// them is supported, in which case an uncompressed fallback should be
+// used.
+func (l *Limits) SelectCompressedFormat(candidates []CompressedFormat, gammaCorrection bool) (CompressedFormat, bool) {
+	for _, format := range compressedFormatPreference {
+		if slices.Contains(candidates, format) && l.SupportsCompressedFormat(format, gammaCorrection) {
+			return format, true
+		}
+	}
+	return 0, false
+}
+
+// CreateCompressedTexture2D creates a 2D texture from compressed data. It
+// returns an error if the format is not supported by the device.
+func (a *API) CreateCompressedTexture2D(info CompressedTexture2DInfo) (render.Texture, error) {
+	glFormat, err := a.compressedGLFormat(info.Format, info.GammaCorrection)
+	if err != nil {
+		return nil, err
+	}
+	layers := make([]internal.CompressedMipmapLayer, len(info.MipmapLayers))
+	for i, layer := range info.MipmapLayers {
+		layers[i] = internal.CompressedMipmapLayer{
+			Width:  layer.Width,
+			Height: layer.Height,
+			Data:   layer.Data,
+		}
+	}
+	texture, err := internal.NewCompressedTexture2D(internal.CompressedTexture2DInfo{
+		Label:          info.Label,
+		InternalFormat: glFormat,
+		MipmapLayers:   layers,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return texture, nil
+}
+
+// CreateCompressedTextureCube creates a cube texture from compressed data.
+// It returns an error if the format is not supported by the device.
+func (a *API) CreateCompressedTextureCube(info CompressedTextureCubeInfo) (render.Texture, error) {
+	glFormat, err := a.compressedGLFormat(info.Format, info.GammaCorrection)
+	if err != nil {
+		return nil, err
+	}
+	layers := make([]internal.CompressedCubeMipmapLayer, len(info.MipmapLayers))
+	for i, layer := range info.MipmapLayers {
+		layers[i] = internal.CompressedCubeMipmapLayer{
+			Dimension: layer.Dimension,
+			SideData: [6][]byte{
+				layer.RightSideData,
+				layer.LeftSideData,
+				layer.BottomSideData,
+				layer.TopSideData,
+				layer.FrontSideData,
+				layer.BackSideData,
+			},
+		}
+	}
+	texture, err := internal.NewCompressedTextureCube(internal.CompressedTextureCubeInfo{
+		Label:          info.Label,
+		InternalFormat: glFormat,
+		MipmapLayers:   layers,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return texture, nil
+}
+
+func (a *API) compressedGLFormat(format CompressedFormat, gammaCorrection bool) (wasmgl.GLenum, error) {
+	if !a.limits.SupportsCompressedFormat(format, gammaCorrection) {
+		return 0, fmt.Errorf("compressed format %s (gamma correction: %t) is not supported", format, gammaCorrection)
+	}
+	info := compressedFormats[format]
+	if gammaCorrection {
+		return info.glFormatSRGB, nil
+	}
+	return info.glFormat, nil
+}
@@ -0,0 +1,45 @@
+package render
+
+import "github.com/mokiat/lacking-js/render/internal"
+
+// The following data formats are supported in addition to the ones that
+// are defined by render.DataFormat. They can be used wherever a
+// render.DataFormat is expected, such as when creating color textures or
+// when copying framebuffer contents to a buffer.
+const (
+	// DataFormatR8 is a single channel 8-bit normalized format.
+	DataFormatR8 = internal.DataFormatR8
+
+	// DataFormatRG8 is a two channel 8-bit normalized format.
+	DataFormatRG8 = internal.DataFormatRG8
+
+	// DataFormatR16F is a single channel half-precision float format.
+	// Rendering to it requires EXT_color_buffer_float.
+	DataFormatR16F = internal.DataFormatR16F
+
+	// DataFormatRG16F is a two channel half-precision float format.
+	// Rendering to it requires EXT_color_buffer_float.
+	DataFormatRG16F = internal.DataFormatRG16F
+
+	// DataFormatR32F is a single channel single-precision float format.
+	// Rendering to it requires EXT_color_buffer_float and it cannot be
+	// linearly filtered without OES_texture_float_linear.
+	DataFormatR32F = internal.DataFormatR32F
+
+	// DataFormatR32UI is a single channel 32-bit unsigned integer format.
+	// It needs to be sampled with a usampler and cannot be filtered.
+	DataFormatR32UI = internal.DataFormatR32UI
+
+	// DataFormatRGBA8UI is a four channel 8-bit unsigned integer format.
+	// It needs to be sampled with a usampler and cannot be filtered.
+	DataFormatRGBA8UI = internal.DataFormatRGBA8UI
+
+	// DataFormatR11G11B10F is a packed unsigned float format without
+	// alpha, suitable for HDR color. Rendering to it requires
+	// EXT_color_buffer_float.
+	DataFormatR11G11B10F = internal.DataFormatR11G11B10F
+
+	// DataFormatRGB10A2 is a packed normalized format with 10-bit color
+	// and 2-bit alpha channels.
+	DataFormatRGB10A2 = internal.DataFormatRGB10A2
+)
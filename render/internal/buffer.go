@@ -17,24 +17,66 @@ func NewIndexBuffer(info render.BufferInfo) *Buffer {
 
 func NewPixelTransferBuffer(info render.BufferInfo) render.Buffer {
 	defer trackError("Error creating pixel transfer buffer", info.Label)()
+	result := &Buffer{
+		label: info.Label,
+		raw:   createPixelTransferBuffer(info),
+		kind:  wasmgl.PIXEL_PACK_BUFFER,
+	}
+	result.recreate = retained(func() {
+		result.raw = createPixelTransferBuffer(info)
+	})
+	result.id = buffers.Allocate(result)
+	return result
+}
+
+func createPixelTransferBuffer(info render.BufferInfo) wasmgl.Buffer {
 	raw := wasmgl.CreateBuffer()
 	wasmgl.BindBuffer(wasmgl.PIXEL_PACK_BUFFER, raw)
 	wasmgl.BufferData(wasmgl.PIXEL_PACK_BUFFER, wasmgl.GLintptr(info.Size), nil, wasmgl.DYNAMIC_READ)
+	return raw
+}
+
+func NewPixelUnpackBuffer(info render.BufferInfo) render.Buffer {
+	defer trackError("Error creating pixel unpack buffer", info.Label)()
 	result := &Buffer{
 		label: info.Label,
-		raw:   raw,
-		kind:  wasmgl.PIXEL_PACK_BUFFER,
+		raw:   createPixelUnpackBuffer(info),
+		kind:  wasmgl.PIXEL_UNPACK_BUFFER,
 	}
+	result.recreate = retained(func() {
+		result.raw = createPixelUnpackBuffer(info)
+	})
 	result.id = buffers.Allocate(result)
 	return result
 }
 
+func createPixelUnpackBuffer(info render.BufferInfo) wasmgl.Buffer {
+	raw := createBuffer(info, wasmgl.PIXEL_UNPACK_BUFFER)
+	// NOTE: Pixel uploads from client memory fail while a pixel unpack
+	// buffer is bound.
+	wasmgl.BindBuffer(wasmgl.PIXEL_UNPACK_BUFFER, wasmgl.NilBuffer)
+	return raw
+}
+
 func NewUniformBuffer(info render.BufferInfo) render.Buffer {
 	defer trackError("Error creating uniform buffer", info.Label)()
 	return newBuffer(info, wasmgl.UNIFORM_BUFFER)
 }
 
 func newBuffer(info render.BufferInfo, kind wasmgl.GLenum) *Buffer {
+	result := &Buffer{
+		label: info.Label,
+		raw:   createBuffer(info, kind),
+		kind:  kind,
+	}
+	result.recreate = retained(func() {
+		result.raw = createBuffer(info, kind)
+	})
+	result.id = buffers.Allocate(result)
+	return result
+}
+
+func createBuffer(info render.BufferInfo, kind wasmgl.GLenum) wasmgl.Buffer {
 	raw := wasmgl.CreateBuffer()
 	wasmgl.BindBuffer(kind, raw)
 	if info.Data != nil {
@@ -42,13 +84,7 @@ func newBuffer(info render.BufferInfo, kind wasmgl.GLenum) *Buffer {
 	} else {
 		wasmgl.BufferData(kind, wasmgl.GLintptr(info.Size), nil, glBufferUsage(info.Dynamic))
 	}
-	result := &Buffer{
-		label: info.Label,
-		raw:   raw,
-		kind:  kind,
-	}
-	result.id = buffers.Allocate(result)
-	return result
+	return raw
 }
 
 type Buffer struct {
@@ -58,6 +94,8 @@ type Buffer struct {
 	id    uint32
 	raw   wasmgl.Buffer
 	kind  wasmgl.GLenum
+
+	recreate func()
 }
 
 func (b *Buffer) Label() string {
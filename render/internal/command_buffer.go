@@ -6,7 +6,6 @@ import (
 	"unsafe"
 
 	"github.com/mokiat/lacking/render"
-	"github.com/mokiat/wasmgl"
 )
 
 func NewCommandBuffer(info render.CommandBufferInfo) *CommandBuffer {
@@ -42,18 +41,10 @@ func (b *CommandBuffer) Reset() {
 
 func (b *CommandBuffer) CopyFramebufferToBuffer(info render.CopyFramebufferToBufferInfo) {
 	b.verifyIsRenderPass()
-	var format, xtype uint32
-	switch info.Format {
-	case render.DataFormatRGBA8:
-		format = wasmgl.RGBA
-		xtype = wasmgl.UNSIGNED_BYTE
-	case render.DataFormatRGBA16F:
-		format = wasmgl.RGBA
-		xtype = wasmgl.HALF_FLOAT
-	case render.DataFormatRGBA32F:
-		format = wasmgl.RGBA
-		xtype = wasmgl.FLOAT
-	default:
+	format := glDataFormat(info.Format)
+	xtype := glDataComponentType(info.Format)
+	// NOTE: This also rejects formats that are not known to this package.
+	if dataFormatFromGL(format, xtype) != info.Format {
 		panic(fmt.Errorf("unsupported data format %v", info.Format))
 	}
 	writeCommandChunk(b, CommandHeader{
@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/mokiat/wasmgl"
+)
+
+type CompressedMipmapLayer struct {
+	Width  uint32
+	Height uint32
+	Data   []byte
+}
+
+type CompressedTexture2DInfo struct {
+	Label          string
+	InternalFormat wasmgl.GLenum
+	MipmapLayers   []CompressedMipmapLayer
+}
+
+type CompressedCubeMipmapLayer struct {
+	Dimension uint32
+	SideData  [6][]byte
+}
+
+type CompressedTextureCubeInfo struct {
+	Label          string
+	InternalFormat wasmgl.GLenum
+	MipmapLayers   []CompressedCubeMipmapLayer
+}
+
+func NewCompressedTexture2D(info CompressedTexture2DInfo) (*Texture, error) {
+	if len(info.MipmapLayers) == 0 {
+		return nil, fmt.Errorf("compressed texture %q has no mipmap layers", info.Label)
+	}
+	if glContext.IsNull() {
+		return nil, fmt.Errorf("compressed texture %q requires the WebGL context to be set", info.Label)
+	}
+	defer trackError("Error creating compressed texture 2D", info.Label)()
+
+	result := &Texture{
+		label:            info.Label,
+		raw:              createCompressedTexture2D(info),
+		kind:             wasmgl.TEXTURE_2D,
+		compressedFormat: info.InternalFormat,
+		width:            info.MipmapLayers[0].Width,
+		height:           info.MipmapLayers[0].Height,
+	}
+	result.recreate = retained(func() {
+		result.raw = createCompressedTexture2D(info)
+	})
+	result.id = textures.Allocate(result)
+	return result, nil
+}
+
+func createCompressedTexture2D(info CompressedTexture2DInfo) wasmgl.Texture {
+	raw := wasmgl.CreateTexture()
+	wasmgl.BindTexture(wasmgl.TEXTURE_2D, raw)
+	wasmgl.TexParameteri(wasmgl.TEXTURE_2D, wasmgl.TEXTURE_WRAP_S, wasmgl.CLAMP_TO_EDGE)
+	wasmgl.TexParameteri(wasmgl.TEXTURE_2D, wasmgl.TEXTURE_WRAP_T, wasmgl.CLAMP_TO_EDGE)
+	wasmgl.TexParameteri(wasmgl.TEXTURE_2D, wasmgl.TEXTURE_MIN_FILTER, wasmgl.NEAREST)
+	wasmgl.TexParameteri(wasmgl.TEXTURE_2D, wasmgl.TEXTURE_MAG_FILTER, wasmgl.NEAREST)
+
+	width := info.MipmapLayers[0].Width
+	height := info.MipmapLayers[0].Height
+	levels := wasmgl.GLsizei(len(info.MipmapLayers))
+	wasmgl.TexStorage2D(wasmgl.TEXTURE_2D, levels, info.InternalFormat, wasmgl.GLsizei(width), wasmgl.GLsizei(height))
+
+	for i, mipmapLayer := range info.MipmapLayers {
+		if mipmapLayer.Data != nil {
+			compressedTexSubImage2D(wasmgl.TEXTURE_2D, uint32(i), mipmapLayer.Width, mipmapLayer.Height, info.InternalFormat, mipmapLayer.Data)
+		}
+	}
+	return raw
+}
+
+func NewCompressedTextureCube(info CompressedTextureCubeInfo) (*Texture, error) {
+	if len(info.MipmapLayers) == 0 {
+		return nil, fmt.Errorf("compressed texture %q has no mipmap layers", info.Label)
+	}
+	if glContext.IsNull() {
+		return nil, fmt.Errorf("compressed texture %q requires the WebGL context to be set", info.Label)
+	}
+	defer trackError("Error creating compressed texture cube", info.Label)()
+
+	result := &Texture{
+		label:            info.Label,
+		raw:              createCompressedTextureCube(info),
+		kind:             wasmgl.TEXTURE_CUBE_MAP,
+		compressedFormat: info.InternalFormat,
+		width:            info.MipmapLayers[0].Dimension,
+		height:           info.MipmapLayers[0].Dimension,
+		depth:            info.MipmapLayers[0].Dimension,
+	}
+	result.recreate = retained(func() {
+		result.raw = createCompressedTextureCube(info)
+	})
+	result.id = textures.Allocate(result)
+	return result, nil
+}
+
+func createCompressedTextureCube(info CompressedTextureCubeInfo) wasmgl.Texture {
+	raw := wasmgl.CreateTexture()
+	wasmgl.BindTexture(wasmgl.TEXTURE_CUBE_MAP, raw)
+	wasmgl.TexParameteri(wasmgl.TEXTURE_CUBE_MAP, wasmgl.TEXTURE_WRAP_S, wasmgl.CLAMP_TO_EDGE)
+	wasmgl.TexParameteri(wasmgl.TEXTURE_CUBE_MAP, wasmgl.TEXTURE_WRAP_T, wasmgl.CLAMP_TO_EDGE)
+	wasmgl.TexParameteri(wasmgl.TEXTURE_CUBE_MAP, wasmgl.TEXTURE_WRAP_R, wasmgl.CLAMP_TO_EDGE)
+	wasmgl.TexParameteri(wasmgl.TEXTURE_CUBE_MAP, wasmgl.TEXTURE_MIN_FILTER, wasmgl.NEAREST)
+	wasmgl.TexParameteri(wasmgl.TEXTURE_CUBE_MAP, wasmgl.TEXTURE_MAG_FILTER, wasmgl.NEAREST)
+
+	dimension := info.MipmapLayers[0].Dimension
+	levels := wasmgl.GLsizei(len(info.MipmapLayers))
+	wasmgl.TexStorage2D(wasmgl.TEXTURE_CUBE_MAP, levels, info.InternalFormat, wasmgl.GLsizei(dimension), wasmgl.GLsizei(dimension))
+
+	for i, mipmapLayer := range info.MipmapLayers {
+		for side, data := range mipmapLayer.SideData {
+			if data != nil {
+				target := wasmgl.TEXTURE_CUBE_MAP_POSITIVE_X + wasmgl.GLenum(side)
+				compressedTexSubImage2D(target, uint32(i), mipmapLayer.Dimension, mipmapLayer.Dimension, info.InternalFormat, data)
+			}
+		}
+	}
+	return raw
+}
+
+// compressedTexSubImage2D uploads a complete mipmap level of compressed
+// data, since wasmgl does not provide compressed uploads.
+func compressedTexSubImage2D(target wasmgl.GLenum, level, width, height uint32, internalFormat wasmgl.GLenum, data []byte) {
+	glContext.Call("compressedTexSubImage2D", target, level, 0, 0, width, height, internalFormat, jsPixelData(data, wasmgl.UNSIGNED_BYTE))
+}
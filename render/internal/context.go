@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"syscall/js"
+
+	"github.com/mokiat/wasmgl"
+)
+
+// glContext is the WebGL2 rendering context that wasmgl was initialized
+// with. Calls should go through wasmgl wherever possible. The context is
+// only used directly for functionality that wasmgl does not expose, which
+// is pixelStorei, uploads from pixel unpack buffer offsets, compressed
+// uploads and uploads of component types that wasmgl cannot convert.
+var glContext = js.Null()
+
+// SetContext specifies the WebGL2 rendering context that wasmgl was
+// initialized with.
+func SetContext(jsContext js.Value) {
+	glContext = jsContext
+}
+
+// uploadBuffer is reused across pixel uploads to avoid allocating a new
+// JavaScript buffer for each one.
+var uploadBuffer = js.Null()
+
+// jsPixelData copies the data to a JavaScript typed array that matches
+// the specified component type, as required by WebGL.
+func jsPixelData(data []byte, componentType wasmgl.GLenum) js.Value {
+	var size int
+	if !uploadBuffer.IsNull() {
+		size = uploadBuffer.Get("byteLength").Int()
+	}
+	if size < len(data) {
+		uploadBuffer = js.Global().Get("ArrayBuffer").New(max(len(data), 2*size))
+	}
+	jsBytes := js.Global().Get("Uint8Array").New(uploadBuffer, 0, len(data))
+	js.CopyBytesToJS(jsBytes, data)
+	switch componentType {
+	case wasmgl.FLOAT:
+		return js.Global().Get("Float32Array").New(uploadBuffer, 0, len(data)/4)
+	case wasmgl.HALF_FLOAT, wasmgl.UNSIGNED_SHORT:
+		return js.Global().Get("Uint16Array").New(uploadBuffer, 0, len(data)/2)
+	case wasmgl.UNSIGNED_INT, wasmgl.UNSIGNED_INT_2_10_10_10_REV, wasmgl.UNSIGNED_INT_10F_11F_11F_REV:
+		return js.Global().Get("Uint32Array").New(uploadBuffer, 0, len(data)/4)
+	default:
+		return jsBytes
+	}
+}
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"log/slog"
+
+	"github.com/mokiat/lacking/render"
+)
+
+// The following data formats extend the ones that are defined by
+// render.DataFormat. They use values that are well outside of its range,
+// so that they do not collide with formats that might be added to it.
+const (
+	DataFormatR8 render.DataFormat = 128 + iota
+	DataFormatRG8
+	DataFormatR16F
+	DataFormatRG16F
+	DataFormatR32F
+	DataFormatR32UI
+	DataFormatRGBA8UI
+	DataFormatR11G11B10F
+	DataFormatRGB10A2
+)
+
+// isIntegerDataFormat returns whether the format holds unnormalized
+// integer values, which cannot be filtered and thus cannot have their
+// mipmaps generated.
+func isIntegerDataFormat(format render.DataFormat) bool {
+	switch format {
+	case DataFormatR32UI, DataFormatRGBA8UI:
+		return true
+	default:
+		return false
+	}
+}
+
+// canGenerateMipmaps returns whether mipmaps can be generated for a
+// texture of the specified format. It logs an error if generation was
+// requested for an integer format.
+func canGenerateMipmaps(label string, format render.DataFormat, generate bool) bool {
+	if generate && isIntegerDataFormat(format) {
+		logger.Error("Mipmaps cannot be generated for integer textures",
+			slog.String("label", label),
+		)
+		return false
+	}
+	return generate
+}
@@ -10,6 +10,20 @@ import (
 func NewFramebuffer(info render.FramebufferInfo) *Framebuffer {
 	defer trackError("Error creating framebuffer", info.Label)()
 
+	raw, activeDrawBuffers := createFramebuffer(info)
+	result := &Framebuffer{
+		label:             info.Label,
+		raw:               raw,
+		activeDrawBuffers: activeDrawBuffers,
+	}
+	result.recreate = retained(func() {
+		result.raw, _ = createFramebuffer(info)
+	})
+	result.id = framebuffers.Allocate(result)
+	return result
+}
+
+func createFramebuffer(info render.FramebufferInfo) (wasmgl.Framebuffer, [4]bool) {
 	raw := wasmgl.CreateFramebuffer()
 	wasmgl.BindFramebuffer(wasmgl.FRAMEBUFFER, raw)
 
@@ -23,7 +37,7 @@ func NewFramebuffer(info render.FramebufferInfo) *Framebuffer {
 		texture := attachment.Texture.(*Texture)
 		attachmentID := wasmgl.COLOR_ATTACHMENT0 + wasmgl.GLenum(i)
 		switch texture.kind {
-		case wasmgl.TEXTURE_2D_ARRAY:
+		case wasmgl.TEXTURE_2D_ARRAY, wasmgl.TEXTURE_3D:
 			wasmgl.FramebufferTextureLayer(wasmgl.FRAMEBUFFER, attachmentID, texture.raw, int32(attachment.MipmapLayer), int32(attachment.Depth))
 		default:
 			wasmgl.FramebufferTexture2D(wasmgl.FRAMEBUFFER, attachmentID, wasmgl.TEXTURE_2D, texture.raw, int32(attachment.MipmapLayer))
@@ -70,14 +84,7 @@ func NewFramebuffer(info render.FramebufferInfo) *Framebuffer {
 	if status != wasmgl.FRAMEBUFFER_COMPLETE {
 		logger.Error("Framebuffer is incomplete", slog.String("label", info.Label))
 	}
-
-	result := &Framebuffer{
-		label:             info.Label,
-		raw:               raw,
-		activeDrawBuffers: activeDrawBuffers,
-	}
-	result.id = framebuffers.Allocate(result)
-	return result
+	return raw, activeDrawBuffers
 }
 
 var DefaultFramebuffer = &Framebuffer{
@@ -97,6 +104,8 @@ type Framebuffer struct {
 	id                uint32
 	raw               wasmgl.Framebuffer
 	activeDrawBuffers [4]bool
+
+	recreate func()
 }
 
 func (f *Framebuffer) Label() string {
@@ -120,20 +129,55 @@ func DetermineContentFormat(framebuffer render.Framebuffer) render.DataFormat {
 	glFormat := wasmgl.GetParameter(
 		wasmgl.IMPLEMENTATION_COLOR_READ_FORMAT,
 	).GLenum()
-	if glFormat != wasmgl.RGBA {
-		return render.DataFormatUnsupported
-	}
 	glType := wasmgl.GetParameter(
 		wasmgl.IMPLEMENTATION_COLOR_READ_TYPE,
 	).GLenum()
-	switch glType {
-	case wasmgl.UNSIGNED_BYTE:
-		return render.DataFormatRGBA8
-	case wasmgl.HALF_FLOAT:
-		return render.DataFormatRGBA16F
-	case wasmgl.FLOAT:
-		return render.DataFormatRGBA32F
-	default:
-		return render.DataFormatUnsupported
+	return dataFormatFromGL(glFormat, glType)
+}
+
+// dataFormatFromGL returns the data format that corresponds to the
+// specified pixel format and component type combination.
+func dataFormatFromGL(glFormat, glType wasmgl.GLenum) render.DataFormat {
+	switch glFormat {
+	case wasmgl.RGBA:
+		switch glType {
+		case wasmgl.UNSIGNED_BYTE:
+			return render.DataFormatRGBA8
+		case wasmgl.HALF_FLOAT:
+			return render.DataFormatRGBA16F
+		case wasmgl.FLOAT:
+			return render.DataFormatRGBA32F
+		case wasmgl.UNSIGNED_INT_2_10_10_10_REV:
+			return DataFormatRGB10A2
+		}
+	case wasmgl.RED:
+		switch glType {
+		case wasmgl.UNSIGNED_BYTE:
+			return DataFormatR8
+		case wasmgl.HALF_FLOAT:
+			return DataFormatR16F
+		case wasmgl.FLOAT:
+			return DataFormatR32F
+		}
+	case wasmgl.RG:
+		switch glType {
+		case wasmgl.UNSIGNED_BYTE:
+			return DataFormatRG8
+		case wasmgl.HALF_FLOAT:
+			return DataFormatRG16F
+		}
+	case wasmgl.RGB:
+		if glType == wasmgl.UNSIGNED_INT_10F_11F_11F_REV {
+			return DataFormatR11G11B10F
+		}
+	case wasmgl.RED_INTEGER:
+		if glType == wasmgl.UNSIGNED_INT {
+			return DataFormatR32UI
+		}
+	case wasmgl.RGBA_INTEGER:
+		if glType == wasmgl.UNSIGNED_BYTE {
+			return DataFormatRGBA8UI
+		}
 	}
+	return render.DataFormatUnsupported
 }
@@ -5,15 +5,30 @@ import (
 	"github.com/mokiat/wasmgl"
 )
 
+// textureExtensions are the WebGL extensions that provide additional
+// texture and render target formats. Querying an extension enables it.
+var textureExtensions = []string{
+	"EXT_color_buffer_float",
+	"WEBGL_compressed_texture_s3tc",
+	"WEBGL_compressed_texture_s3tc_srgb",
+	"EXT_texture_compression_rgtc",
+	"EXT_texture_compression_bptc",
+	"WEBGL_compressed_texture_etc",
+	"WEBGL_compressed_texture_astc",
+}
+
 func NewLimits() *Limits {
 	uniformBufferOffsetAlignment := wasmgl.GetParameter(wasmgl.UNIFORM_BUFFER_OFFSET_ALIGNMENT).GLint()
-	return &Limits{
+	result := &Limits{
 		uniformBufferOffsetAlignment: int(uniformBufferOffsetAlignment),
 	}
+	result.DetectExtensions()
+	return result
 }
 
 type Limits struct {
 	uniformBufferOffsetAlignment int
+	extensions                   map[string]bool
 }
 
 func (l Limits) UniformBufferOffsetAlignment() int {
@@ -23,3 +38,19 @@ func (l Limits) UniformBufferOffsetAlignment() int {
 func (l Limits) Quality() render.Quality {
 	return render.QualityHigh
 }
+
+// SupportsExtension returns whether the specified texture extension is
+// available and enabled.
+func (l Limits) SupportsExtension(name string) bool {
+	return l.extensions[name]
+}
+
+// DetectExtensions enables the texture extensions that are supported by
+// the browser. It needs to be repeated when the WebGL context is
+// restored.
+func (l *Limits) DetectExtensions() {
+	l.extensions = make(map[string]bool, len(textureExtensions))
+	for _, name := range textureExtensions {
+		l.extensions[name] = wasmgl.GetExtension(name) != nil
+	}
+}
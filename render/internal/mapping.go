@@ -27,6 +27,12 @@ func (m *Mapper[T]) Get(id uint32) T {
 	return m.mapping[id]
 }
 
+func (m *Mapper[T]) Each(fn func(v T)) {
+	for _, v := range m.mapping {
+		fn(v)
+	}
+}
+
 var (
 	framebuffers = newMapper[*Framebuffer]()
 	programs     = newMapper[*Program]()
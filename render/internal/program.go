@@ -17,24 +17,54 @@ type ProgramInfo struct {
 }
 
 func NewProgram(info ProgramInfo) *Program {
+	program := &Program{
+		label: info.Label,
+	}
+	program.create(info)
+	program.recreate = retained(func() {
+		program.create(info)
+	})
+	program.id = programs.Allocate(program)
+	return program
+}
+
+type Program struct {
+	render.ProgramMarker
+
+	label string
+	id    uint32
+	raw   wasmgl.Program
+
+	recreate func()
+}
+
+func (p *Program) Label() string {
+	return p.label
+}
+
+func (p *Program) Release() {
+	programs.Release(p.id)
+	wasmgl.DeleteProgram(p.raw)
+	p.raw = wasmgl.NilProgram
+	p.id = 0
+}
+
+func (p *Program) create(info ProgramInfo) {
 	vertexShader := newVertexShader(info.Label, info.VertexCode)
 	defer vertexShader.Release()
 
 	fragmentShader := newFragmentShader(info.Label, info.FragmentCode)
 	defer fragmentShader.Release()
 
-	program := &Program{
-		label: info.Label,
-		raw:   wasmgl.CreateProgram(),
-	}
+	p.raw = wasmgl.CreateProgram()
 
-	wasmgl.AttachShader(program.raw, vertexShader.raw)
-	defer wasmgl.DetachShader(program.raw, vertexShader.raw)
+	wasmgl.AttachShader(p.raw, vertexShader.raw)
+	defer wasmgl.DetachShader(p.raw, vertexShader.raw)
 
-	wasmgl.AttachShader(program.raw, fragmentShader.raw)
-	defer wasmgl.DetachShader(program.raw, fragmentShader.raw)
+	wasmgl.AttachShader(p.raw, fragmentShader.raw)
+	defer wasmgl.DetachShader(p.raw, fragmentShader.raw)
 
-	if err := program.link(); err != nil {
+	if err := p.link(); err != nil {
 		logger.Error("Program link error",
 			slog.String("label", info.Label),
 			slog.String("error", err.Error()),
@@ -42,9 +72,9 @@ func NewProgram(info ProgramInfo) *Program {
 	}
 
 	if len(info.TextureBindings) > 0 {
-		wasmgl.UseProgram(program.raw)
+		wasmgl.UseProgram(p.raw)
 		for _, binding := range info.TextureBindings {
-			location := wasmgl.GetUniformLocation(program.raw, binding.Name)
+			location := wasmgl.GetUniformLocation(p.raw, binding.Name)
 			if location.IsValid() {
 				wasmgl.Uniform1i(location, wasmgl.GLint(binding.Index))
 			}
@@ -53,33 +83,11 @@ func NewProgram(info ProgramInfo) *Program {
 	}
 
 	for _, binding := range info.UniformBindings {
-		location := wasmgl.GetUniformBlockIndex(program.raw, binding.Name)
+		location := wasmgl.GetUniformBlockIndex(p.raw, binding.Name)
 		if location != wasmgl.INVALID_INDEX {
-			wasmgl.UniformBlockBinding(program.raw, location, wasmgl.GLuint(binding.Index))
+			wasmgl.UniformBlockBinding(p.raw, location, wasmgl.GLuint(binding.Index))
 		}
 	}
-
-	program.id = programs.Allocate(program)
-	return program
-}
-
-type Program struct {
-	render.ProgramMarker
-
-	label string
-	id    uint32
-	raw   wasmgl.Program
-}
-
-func (p *Program) Label() string {
-	return p.label
-}
-
-func (p *Program) Release() {
-	programs.Release(p.id)
-	wasmgl.DeleteProgram(p.raw)
-	p.raw = wasmgl.NilProgram
-	p.id = 0
 }
 
 func (p *Program) link() error {
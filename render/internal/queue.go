@@ -195,7 +195,7 @@ func (q *Queue) executeCommandCopyFramebufferToTexture(command CommandCopyFrameb
 		command.Width,
 		command.Height,
 	)
-	if command.GenerateMipmaps {
+	if canGenerateMipmaps(intTexture.label, intTexture.format, command.GenerateMipmaps) {
 		wasmgl.GenerateMipmap(intTexture.kind)
 	}
 }
@@ -0,0 +1,54 @@
+package internal
+
+// retainResources controls whether newly created resources keep the
+// information that was used to create them, so that they can be
+// recreated after the WebGL context is lost.
+var retainResources = false
+
+// SetRetainResources specifies whether resources that are created from
+// now on should retain their creation information (including any initial
+// data) for the purpose of context restoration.
+func SetRetainResources(retain bool) {
+	retainResources = retain
+}
+
+// RestoreResources recreates the WebGL objects of all live resources that
+// were created while resource retention was enabled. It should be called
+// once the WebGL context has been restored. Resources that were created
+// without retention are left invalid, as is any content that was written
+// to resources after they were created.
+func RestoreResources() {
+	// Vertex arrays and framebuffers reference buffers and textures, so
+	// they need to be recreated last.
+	buffers.Each(func(buffer *Buffer) {
+		recreate(buffer.recreate)
+	})
+	textures.Each(func(texture *Texture) {
+		recreate(texture.recreate)
+	})
+	samplers.Each(func(sampler *Sampler) {
+		recreate(sampler.recreate)
+	})
+	programs.Each(func(program *Program) {
+		recreate(program.recreate)
+	})
+	vertexArrays.Each(func(vertexArray *VertexArray) {
+		recreate(vertexArray.recreate)
+	})
+	framebuffers.Each(func(framebuffer *Framebuffer) {
+		recreate(framebuffer.recreate)
+	})
+}
+
+func retained(fn func()) func() {
+	if !retainResources {
+		return nil
+	}
+	return fn
+}
+
+func recreate(fn func()) {
+	if fn != nil {
+		fn()
+	}
+}
@@ -1,13 +1,32 @@
 package internal
 
 import (
+	"fmt"
+
 	"github.com/mokiat/lacking/render"
 	"github.com/mokiat/wasmgl"
 )
 
 func NewColorTexture2D(info render.ColorTexture2DInfo) *Texture {
 	defer trackError("Error creating color texture 2D", info.Label)()
+	info.GenerateMipmaps = canGenerateMipmaps(info.Label, info.Format, info.GenerateMipmaps)
+
+	result := &Texture{
+		label:  info.Label,
+		raw:    createColorTexture2D(info),
+		kind:   wasmgl.TEXTURE_2D,
+		format: info.Format,
+		width:  info.MipmapLayers[0].Width,
+		height: info.MipmapLayers[0].Height,
+	}
+	result.recreate = retained(func() {
+		result.raw = createColorTexture2D(info)
+	})
+	result.id = textures.Allocate(result)
+	return result
+}
 
+func createColorTexture2D(info render.ColorTexture2DInfo) wasmgl.Texture {
 	raw := wasmgl.CreateTexture()
 	wasmgl.BindTexture(wasmgl.TEXTURE_2D, raw)
 	wasmgl.TexParameteri(wasmgl.TEXTURE_2D, wasmgl.TEXTURE_WRAP_S, wasmgl.CLAMP_TO_EDGE)
@@ -29,7 +48,7 @@ func NewColorTexture2D(info render.ColorTexture2DInfo) *Texture {
 	componentType := glDataComponentType(info.Format)
 	for i, mipmapLayer := range info.MipmapLayers {
 		if mipmapLayer.Data != nil {
-			wasmgl.TexSubImage2D(wasmgl.TEXTURE_2D, int32(i), 0, 0, wasmgl.GLsizei(mipmapLayer.Width), wasmgl.GLsizei(mipmapLayer.Height), dataFormat, componentType, mipmapLayer.Data)
+			texSubImage2D(wasmgl.TEXTURE_2D, int32(i), 0, 0, wasmgl.GLsizei(mipmapLayer.Width), wasmgl.GLsizei(mipmapLayer.Height), dataFormat, componentType, mipmapLayer.Data)
 		}
 	}
 
@@ -37,21 +56,136 @@ func NewColorTexture2D(info render.ColorTexture2DInfo) *Texture {
 		// TODO: Move as separate command
 		wasmgl.GenerateMipmap(wasmgl.TEXTURE_2D)
 	}
+	return raw
+}
+
+type MipmapVolumeLayer struct {
+	Width  uint32
+	Height uint32
+	Depth  uint32
+	Data   []byte
+}
+
+type ColorTexture2DArrayInfo struct {
+	Label           string
+	MipmapLayers    []MipmapVolumeLayer
+	GenerateMipmaps bool
+	GammaCorrection bool
+	Format          render.DataFormat
+}
+
+func NewColorTexture2DArray(info ColorTexture2DArrayInfo) (*Texture, error) {
+	if len(info.MipmapLayers) == 0 {
+		return nil, fmt.Errorf("color texture 2D array %q has no mipmap layers", info.Label)
+	}
+	defer trackError("Error creating color texture 2D array", info.Label)()
+	info.GenerateMipmaps = canGenerateMipmaps(info.Label, info.Format, info.GenerateMipmaps)
 
 	result := &Texture{
 		label:  info.Label,
-		raw:    raw,
-		kind:   wasmgl.TEXTURE_2D,
-		width:  width,
-		height: height,
+		raw:    createColorTextureVolume(wasmgl.TEXTURE_2D_ARRAY, info.MipmapLayers, info.GenerateMipmaps, info.GammaCorrection, info.Format),
+		kind:   wasmgl.TEXTURE_2D_ARRAY,
+		format: info.Format,
+		width:  info.MipmapLayers[0].Width,
+		height: info.MipmapLayers[0].Height,
+		depth:  info.MipmapLayers[0].Depth,
 	}
+	result.recreate = retained(func() {
+		result.raw = createColorTextureVolume(wasmgl.TEXTURE_2D_ARRAY, info.MipmapLayers, info.GenerateMipmaps, info.GammaCorrection, info.Format)
+	})
 	result.id = textures.Allocate(result)
-	return result
+	return result, nil
+}
+
+type ColorTexture3DInfo struct {
+	Label           string
+	MipmapLayers    []MipmapVolumeLayer
+	GenerateMipmaps bool
+	GammaCorrection bool
+	Format          render.DataFormat
+}
+
+func NewColorTexture3D(info ColorTexture3DInfo) (*Texture, error) {
+	if len(info.MipmapLayers) == 0 {
+		return nil, fmt.Errorf("color texture 3D %q has no mipmap layers", info.Label)
+	}
+	defer trackError("Error creating color texture 3D", info.Label)()
+	info.GenerateMipmaps = canGenerateMipmaps(info.Label, info.Format, info.GenerateMipmaps)
+
+	result := &Texture{
+		label:  info.Label,
+		raw:    createColorTextureVolume(wasmgl.TEXTURE_3D, info.MipmapLayers, info.GenerateMipmaps, info.GammaCorrection, info.Format),
+		kind:   wasmgl.TEXTURE_3D,
+		format: info.Format,
+		width:  info.MipmapLayers[0].Width,
+		height: info.MipmapLayers[0].Height,
+		depth:  info.MipmapLayers[0].Depth,
+	}
+	result.recreate = retained(func() {
+		result.raw = createColorTextureVolume(wasmgl.TEXTURE_3D, info.MipmapLayers, info.GenerateMipmaps, info.GammaCorrection, info.Format)
+	})
+	result.id = textures.Allocate(result)
+	return result, nil
+}
+
+// createColorTextureVolume creates a 2D array or 3D texture. For arrays,
+// the depth of each mipmap layer is the number of array layers.
+func createColorTextureVolume(kind wasmgl.GLenum, mipmapLayers []MipmapVolumeLayer, generateMipmaps, gammaCorrection bool, format render.DataFormat) wasmgl.Texture {
+	raw := wasmgl.CreateTexture()
+	wasmgl.BindTexture(kind, raw)
+	wasmgl.TexParameteri(kind, wasmgl.TEXTURE_WRAP_S, wasmgl.CLAMP_TO_EDGE)
+	wasmgl.TexParameteri(kind, wasmgl.TEXTURE_WRAP_T, wasmgl.CLAMP_TO_EDGE)
+	wasmgl.TexParameteri(kind, wasmgl.TEXTURE_WRAP_R, wasmgl.CLAMP_TO_EDGE)
+	wasmgl.TexParameteri(kind, wasmgl.TEXTURE_MIN_FILTER, wasmgl.NEAREST)
+	wasmgl.TexParameteri(kind, wasmgl.TEXTURE_MAG_FILTER, wasmgl.NEAREST)
+
+	width := mipmapLayers[0].Width
+	height := mipmapLayers[0].Height
+	depth := mipmapLayers[0].Depth
+	internalFormat := glInternalFormat(format, gammaCorrection)
+	levels := wasmgl.GLsizei(len(mipmapLayers))
+	if levels == 1 {
+		if kind == wasmgl.TEXTURE_3D {
+			// NOTE: Mipmaps of 3D textures shrink in all three dimensions.
+			levels = glMipmapLevels(max(width, depth), height, generateMipmaps)
+		} else {
+			levels = glMipmapLevels(width, height, generateMipmaps)
+		}
+	}
+	wasmgl.TexStorage3D(kind, levels, internalFormat, wasmgl.GLsizei(width), wasmgl.GLsizei(height), wasmgl.GLsizei(depth))
+
+	dataFormat := glDataFormat(format)
+	componentType := glDataComponentType(format)
+	for i, mipmapLayer := range mipmapLayers {
+		if mipmapLayer.Data != nil {
+			texSubImage3D(kind, int32(i), 0, 0, 0, wasmgl.GLsizei(mipmapLayer.Width), wasmgl.GLsizei(mipmapLayer.Height), wasmgl.GLsizei(mipmapLayer.Depth), dataFormat, componentType, mipmapLayer.Data)
+		}
+	}
+
+	if generateMipmaps && len(mipmapLayers) == 1 {
+		wasmgl.GenerateMipmap(kind)
+	}
+	return raw
 }
 
 func NewDepthTexture2D(info render.DepthTexture2DInfo) *Texture {
 	defer trackError("Error creating depth texture 2D", info.Label)()
 
+	result := &Texture{
+		label:  info.Label,
+		raw:    createDepthTexture2D(info),
+		kind:   wasmgl.TEXTURE_2D,
+		width:  info.Width,
+		height: info.Height,
+	}
+	result.recreate = retained(func() {
+		result.raw = createDepthTexture2D(info)
+	})
+	result.id = textures.Allocate(result)
+	return result
+}
+
+func createDepthTexture2D(info render.DepthTexture2DInfo) wasmgl.Texture {
 	raw := wasmgl.CreateTexture()
 	wasmgl.BindTexture(wasmgl.TEXTURE_2D, raw)
 	wasmgl.TexParameteri(wasmgl.TEXTURE_2D, wasmgl.TEXTURE_WRAP_S, wasmgl.CLAMP_TO_EDGE)
@@ -64,21 +198,27 @@ func NewDepthTexture2D(info render.DepthTexture2DInfo) *Texture {
 	} else {
 		wasmgl.TexStorage2D(wasmgl.TEXTURE_2D, 1, wasmgl.DEPTH_COMPONENT24, wasmgl.GLsizei(info.Width), wasmgl.GLsizei(info.Height))
 	}
+	return raw
+}
+
+func NewDepthTexture2DArray(info render.DepthTexture2DArrayInfo) *Texture {
+	defer trackError("Error creating array depth texture 2D", info.Label)()
 
 	result := &Texture{
 		label:  info.Label,
-		raw:    raw,
-		kind:   wasmgl.TEXTURE_2D,
+		raw:    createDepthTexture2DArray(info),
+		kind:   wasmgl.TEXTURE_2D_ARRAY,
 		width:  info.Width,
 		height: info.Height,
 	}
+	result.recreate = retained(func() {
+		result.raw = createDepthTexture2DArray(info)
+	})
 	result.id = textures.Allocate(result)
 	return result
 }
 
-func NewDepthTexture2DArray(info render.DepthTexture2DArrayInfo) *Texture {
-	defer trackError("Error creating array depth texture 2D", info.Label)()
-
+func createDepthTexture2DArray(info render.DepthTexture2DArrayInfo) wasmgl.Texture {
 	raw := wasmgl.CreateTexture()
 	wasmgl.BindTexture(wasmgl.TEXTURE_2D_ARRAY, raw)
 	wasmgl.TexParameteri(wasmgl.TEXTURE_2D_ARRAY, wasmgl.TEXTURE_WRAP_S, wasmgl.CLAMP_TO_EDGE)
@@ -91,21 +231,27 @@ func NewDepthTexture2DArray(info render.DepthTexture2DArrayInfo) *Texture {
 	} else {
 		wasmgl.TexStorage3D(wasmgl.TEXTURE_2D_ARRAY, 1, wasmgl.DEPTH_COMPONENT24, wasmgl.GLsizei(info.Width), wasmgl.GLsizei(info.Height), wasmgl.GLsizei(info.Layers))
 	}
+	return raw
+}
+
+func NewStencilTexture2D(info render.StencilTexture2DInfo) *Texture {
+	defer trackError("Error creating stencil texture 2D", info.Label)()
 
 	result := &Texture{
 		label:  info.Label,
-		raw:    raw,
-		kind:   wasmgl.TEXTURE_2D_ARRAY,
+		raw:    createStencilTexture2D(info),
+		kind:   wasmgl.TEXTURE_2D,
 		width:  info.Width,
 		height: info.Height,
 	}
+	result.recreate = retained(func() {
+		result.raw = createStencilTexture2D(info)
+	})
 	result.id = textures.Allocate(result)
 	return result
 }
 
-func NewStencilTexture2D(info render.StencilTexture2DInfo) *Texture {
-	defer trackError("Error creating stencil texture 2D", info.Label)()
-
+func createStencilTexture2D(info render.StencilTexture2DInfo) wasmgl.Texture {
 	raw := wasmgl.CreateTexture()
 	wasmgl.BindTexture(wasmgl.TEXTURE_2D, raw)
 	wasmgl.TexParameteri(wasmgl.TEXTURE_2D, wasmgl.TEXTURE_WRAP_S, wasmgl.CLAMP_TO_EDGE)
@@ -114,20 +260,27 @@ func NewStencilTexture2D(info render.StencilTexture2DInfo) *Texture {
 	wasmgl.TexParameteri(wasmgl.TEXTURE_2D, wasmgl.TEXTURE_MAG_FILTER, wasmgl.NEAREST)
 	// NOTE: Firefox does not support wasmgl.STENCIL_INDEX8
 	wasmgl.TexStorage2D(wasmgl.TEXTURE_2D, 1, wasmgl.DEPTH24_STENCIL8, wasmgl.GLsizei(info.Width), wasmgl.GLsizei(info.Height))
+	return raw
+}
+
+func NewDepthStencilTexture2D(info render.DepthStencilTexture2DInfo) *Texture {
+	defer trackError("Error creating depth-stencil texture 2D", info.Label)()
+
 	result := &Texture{
 		label:  info.Label,
-		raw:    raw,
+		raw:    createDepthStencilTexture2D(info),
 		kind:   wasmgl.TEXTURE_2D,
 		width:  info.Width,
 		height: info.Height,
 	}
+	result.recreate = retained(func() {
+		result.raw = createDepthStencilTexture2D(info)
+	})
 	result.id = textures.Allocate(result)
 	return result
 }
 
-func NewDepthStencilTexture2D(info render.DepthStencilTexture2DInfo) *Texture {
-	defer trackError("Error creating depth-stencil texture 2D", info.Label)()
-
+func createDepthStencilTexture2D(info render.DepthStencilTexture2DInfo) wasmgl.Texture {
 	raw := wasmgl.CreateTexture()
 	wasmgl.BindTexture(wasmgl.TEXTURE_2D, raw)
 	wasmgl.TexParameteri(wasmgl.TEXTURE_2D, wasmgl.TEXTURE_WRAP_S, wasmgl.CLAMP_TO_EDGE)
@@ -135,20 +288,30 @@ func NewDepthStencilTexture2D(info render.DepthStencilTexture2DInfo) *Texture {
 	wasmgl.TexParameteri(wasmgl.TEXTURE_2D, wasmgl.TEXTURE_MIN_FILTER, wasmgl.NEAREST)
 	wasmgl.TexParameteri(wasmgl.TEXTURE_2D, wasmgl.TEXTURE_MAG_FILTER, wasmgl.NEAREST)
 	wasmgl.TexStorage2D(wasmgl.TEXTURE_2D, 1, wasmgl.DEPTH24_STENCIL8, wasmgl.GLsizei(info.Width), wasmgl.GLsizei(info.Height))
+	return raw
+}
+
+func NewColorTextureCube(info render.ColorTextureCubeInfo) *Texture {
+	defer trackError("Error creating color texture cube", info.Label)()
+	info.GenerateMipmaps = canGenerateMipmaps(info.Label, info.Format, info.GenerateMipmaps)
+
 	result := &Texture{
 		label:  info.Label,
-		raw:    raw,
-		kind:   wasmgl.TEXTURE_2D,
-		width:  info.Width,
-		height: info.Height,
+		raw:    createColorTextureCube(info),
+		kind:   wasmgl.TEXTURE_CUBE_MAP,
+		format: info.Format,
+		width:  info.MipmapLayers[0].Dimension,
+		height: info.MipmapLayers[0].Dimension,
+		depth:  info.MipmapLayers[0].Dimension,
 	}
+	result.recreate = retained(func() {
+		result.raw = createColorTextureCube(info)
+	})
 	result.id = textures.Allocate(result)
 	return result
 }
 
-func NewColorTextureCube(info render.ColorTextureCubeInfo) *Texture {
-	defer trackError("Error creating color texture cube", info.Label)()
-
+func createColorTextureCube(info render.ColorTextureCubeInfo) wasmgl.Texture {
 	raw := wasmgl.CreateTexture()
 	wasmgl.BindTexture(wasmgl.TEXTURE_CUBE_MAP, raw)
 	wasmgl.TexParameteri(wasmgl.TEXTURE_CUBE_MAP, wasmgl.TEXTURE_WRAP_S, wasmgl.CLAMP_TO_EDGE)
@@ -170,22 +333,22 @@ func NewColorTextureCube(info render.ColorTextureCubeInfo) *Texture {
 	componentType := glDataComponentType(info.Format)
 	for i, mipmapLayer := range info.MipmapLayers {
 		if mipmapLayer.RightSideData != nil {
-			wasmgl.TexSubImage2D(wasmgl.TEXTURE_CUBE_MAP_POSITIVE_X, int32(i), 0, 0, wasmgl.GLsizei(mipmapLayer.Dimension), wasmgl.GLsizei(mipmapLayer.Dimension), dataFormat, componentType, mipmapLayer.RightSideData)
+			texSubImage2D(wasmgl.TEXTURE_CUBE_MAP_POSITIVE_X, int32(i), 0, 0, wasmgl.GLsizei(mipmapLayer.Dimension), wasmgl.GLsizei(mipmapLayer.Dimension), dataFormat, componentType, mipmapLayer.RightSideData)
 		}
 		if mipmapLayer.LeftSideData != nil {
-			wasmgl.TexSubImage2D(wasmgl.TEXTURE_CUBE_MAP_NEGATIVE_X, int32(i), 0, 0, wasmgl.GLsizei(mipmapLayer.Dimension), wasmgl.GLsizei(mipmapLayer.Dimension), dataFormat, componentType, mipmapLayer.LeftSideData)
+			texSubImage2D(wasmgl.TEXTURE_CUBE_MAP_NEGATIVE_X, int32(i), 0, 0, wasmgl.GLsizei(mipmapLayer.Dimension), wasmgl.GLsizei(mipmapLayer.Dimension), dataFormat, componentType, mipmapLayer.LeftSideData)
 		}
 		if mipmapLayer.BottomSideData != nil {
-			wasmgl.TexSubImage2D(wasmgl.TEXTURE_CUBE_MAP_POSITIVE_Y, int32(i), 0, 0, wasmgl.GLsizei(mipmapLayer.Dimension), wasmgl.GLsizei(mipmapLayer.Dimension), dataFormat, componentType, mipmapLayer.BottomSideData)
+			texSubImage2D(wasmgl.TEXTURE_CUBE_MAP_POSITIVE_Y, int32(i), 0, 0, wasmgl.GLsizei(mipmapLayer.Dimension), wasmgl.GLsizei(mipmapLayer.Dimension), dataFormat, componentType, mipmapLayer.BottomSideData)
 		}
 		if mipmapLayer.TopSideData != nil {
-			wasmgl.TexSubImage2D(wasmgl.TEXTURE_CUBE_MAP_NEGATIVE_Y, int32(i), 0, 0, wasmgl.GLsizei(mipmapLayer.Dimension), wasmgl.GLsizei(mipmapLayer.Dimension), dataFormat, componentType, mipmapLayer.TopSideData)
+			texSubImage2D(wasmgl.TEXTURE_CUBE_MAP_NEGATIVE_Y, int32(i), 0, 0, wasmgl.GLsizei(mipmapLayer.Dimension), wasmgl.GLsizei(mipmapLayer.Dimension), dataFormat, componentType, mipmapLayer.TopSideData)
 		}
 		if mipmapLayer.FrontSideData != nil {
-			wasmgl.TexSubImage2D(wasmgl.TEXTURE_CUBE_MAP_POSITIVE_Z, int32(i), 0, 0, wasmgl.GLsizei(mipmapLayer.Dimension), wasmgl.GLsizei(mipmapLayer.Dimension), dataFormat, componentType, mipmapLayer.FrontSideData)
+			texSubImage2D(wasmgl.TEXTURE_CUBE_MAP_POSITIVE_Z, int32(i), 0, 0, wasmgl.GLsizei(mipmapLayer.Dimension), wasmgl.GLsizei(mipmapLayer.Dimension), dataFormat, componentType, mipmapLayer.FrontSideData)
 		}
 		if mipmapLayer.BackSideData != nil {
-			wasmgl.TexSubImage2D(wasmgl.TEXTURE_CUBE_MAP_NEGATIVE_Z, int32(i), 0, 0, wasmgl.GLsizei(mipmapLayer.Dimension), wasmgl.GLsizei(mipmapLayer.Dimension), dataFormat, componentType, mipmapLayer.BackSideData)
+			texSubImage2D(wasmgl.TEXTURE_CUBE_MAP_NEGATIVE_Z, int32(i), 0, 0, wasmgl.GLsizei(mipmapLayer.Dimension), wasmgl.GLsizei(mipmapLayer.Dimension), dataFormat, componentType, mipmapLayer.BackSideData)
 		}
 	}
 
@@ -193,17 +356,7 @@ func NewColorTextureCube(info render.ColorTextureCubeInfo) *Texture {
 		// TODO: Move as separate command
 		wasmgl.GenerateMipmap(wasmgl.TEXTURE_CUBE_MAP)
 	}
-
-	result := &Texture{
-		label:  info.Label,
-		raw:    raw,
-		kind:   wasmgl.TEXTURE_CUBE_MAP,
-		width:  dimension,
-		height: dimension,
-		depth:  dimension,
-	}
-	result.id = textures.Allocate(result)
-	return result
+	return raw
 }
 
 type Texture struct {
@@ -213,9 +366,16 @@ type Texture struct {
 	id     uint32
 	raw    wasmgl.Texture
 	kind   wasmgl.GLenum
+	format render.DataFormat
 	width  uint32
 	height uint32
 	depth  uint32
+
+	// compressedFormat is the internal format of compressed textures and
+	// zero for uncompressed ones.
+	compressedFormat wasmgl.GLenum
+
+	recreate func()
 }
 
 func (t *Texture) Label() string {
@@ -244,6 +404,18 @@ func (t *Texture) Release() {
 func NewSampler(info render.SamplerInfo) *Sampler {
 	defer trackError("Error creating sampler", info.Label)()
 
+	result := &Sampler{
+		label: info.Label,
+		raw:   createSampler(info),
+	}
+	result.recreate = retained(func() {
+		result.raw = createSampler(info)
+	})
+	result.id = samplers.Allocate(result)
+	return result
+}
+
+func createSampler(info render.SamplerInfo) wasmgl.Sampler {
 	raw := wasmgl.CreateSampler()
 	wasmgl.SamplerParameteri(raw, wasmgl.TEXTURE_WRAP_S, glWrap(info.Wrapping))
 	wasmgl.SamplerParameteri(raw, wasmgl.TEXTURE_WRAP_T, glWrap(info.Wrapping))
@@ -254,13 +426,7 @@ func NewSampler(info render.SamplerInfo) *Sampler {
 		wasmgl.SamplerParameteri(raw, wasmgl.TEXTURE_COMPARE_MODE, wasmgl.COMPARE_REF_TO_TEXTURE)
 		wasmgl.SamplerParameteri(raw, wasmgl.TEXTURE_COMPARE_FUNC, int32(glEnumFromComparison(info.Comparison.Value)))
 	}
-
-	result := &Sampler{
-		label: info.Label,
-		raw:   raw,
-	}
-	result.id = samplers.Allocate(result)
-	return result
+	return raw
 }
 
 type Sampler struct {
@@ -269,6 +435,8 @@ type Sampler struct {
 	label string
 	id    uint32
 	raw   wasmgl.Sampler
+
+	recreate func()
 }
 
 func (s *Sampler) Label() string {
@@ -336,6 +504,24 @@ func glInternalFormat(format render.DataFormat, gammaCorrection bool) wasmgl.GLe
 		return wasmgl.RGBA16F
 	case render.DataFormatRGBA32F:
 		return wasmgl.RGBA32F
+	case DataFormatR8:
+		return wasmgl.R8
+	case DataFormatRG8:
+		return wasmgl.RG8
+	case DataFormatR16F:
+		return wasmgl.R16F
+	case DataFormatRG16F:
+		return wasmgl.RG16F
+	case DataFormatR32F:
+		return wasmgl.R32F
+	case DataFormatR32UI:
+		return wasmgl.R32UI
+	case DataFormatRGBA8UI:
+		return wasmgl.RGBA8UI
+	case DataFormatR11G11B10F:
+		return wasmgl.R11F_G11F_B10F
+	case DataFormatRGB10A2:
+		return wasmgl.RGB10_A2
 	default:
 		return wasmgl.RGBA8
 	}
@@ -343,6 +529,16 @@ func glInternalFormat(format render.DataFormat, gammaCorrection bool) wasmgl.GLe
 
 func glDataFormat(format render.DataFormat) wasmgl.GLenum {
 	switch format {
+	case DataFormatR8, DataFormatR16F, DataFormatR32F:
+		return wasmgl.RED
+	case DataFormatRG8, DataFormatRG16F:
+		return wasmgl.RG
+	case DataFormatR32UI:
+		return wasmgl.RED_INTEGER
+	case DataFormatRGBA8UI:
+		return wasmgl.RGBA_INTEGER
+	case DataFormatR11G11B10F:
+		return wasmgl.RGB
 	default:
 		return wasmgl.RGBA
 	}
@@ -350,13 +546,41 @@ func glDataFormat(format render.DataFormat) wasmgl.GLenum {
 
 func glDataComponentType(format render.DataFormat) wasmgl.GLenum {
 	switch format {
-	case render.DataFormatRGBA8:
+	case render.DataFormatRGBA8, DataFormatR8, DataFormatRG8, DataFormatRGBA8UI:
 		return wasmgl.UNSIGNED_BYTE
-	case render.DataFormatRGBA16F:
+	case render.DataFormatRGBA16F, DataFormatR16F, DataFormatRG16F:
 		return wasmgl.HALF_FLOAT
-	case render.DataFormatRGBA32F:
+	case render.DataFormatRGBA32F, DataFormatR32F:
 		return wasmgl.FLOAT
+	case DataFormatR32UI:
+		return wasmgl.UNSIGNED_INT
+	case DataFormatR11G11B10F:
+		return wasmgl.UNSIGNED_INT_10F_11F_11F_REV
+	case DataFormatRGB10A2:
+		return wasmgl.UNSIGNED_INT_2_10_10_10_REV
 	default:
 		return wasmgl.UNSIGNED_BYTE
 	}
 }
+
+// texSubImage2D uploads pixel data to a texture. Component types that
+// wasmgl does not handle are uploaded through the WebGL context directly.
+func texSubImage2D(target wasmgl.GLenum, level, xoffset, yoffset wasmgl.GLint, width, height wasmgl.GLsizei, format, componentType wasmgl.GLenum, data []byte) {
+	switch componentType {
+	case wasmgl.UNSIGNED_BYTE, wasmgl.HALF_FLOAT, wasmgl.FLOAT:
+		wasmgl.TexSubImage2D(target, level, xoffset, yoffset, width, height, format, componentType, data)
+	default:
+		glContext.Call("texSubImage2D", target, level, xoffset, yoffset, width, height, format, componentType, jsPixelData(data, componentType))
+	}
+}
+
+// texSubImage3D uploads pixel data to an array or 3D texture. The wasmgl
+// implementation only handles byte data, so the rest is uploaded through
+// the WebGL context directly.
+func texSubImage3D(target wasmgl.GLenum, level, xoffset, yoffset, zoffset wasmgl.GLint, width, height, depth wasmgl.GLsizei, format, componentType wasmgl.GLenum, data []byte) {
+	if componentType == wasmgl.UNSIGNED_BYTE {
+		wasmgl.TexSubImage3D(target, level, xoffset, yoffset, zoffset, width, height, depth, format, componentType, data)
+		return
+	}
+	glContext.Call("texSubImage3D", target, level, xoffset, yoffset, zoffset, width, height, depth, format, componentType, jsPixelData(data, componentType))
+}
@@ -0,0 +1,179 @@
+package internal
+
+import (
+	"log/slog"
+
+	"github.com/mokiat/lacking/render"
+	"github.com/mokiat/wasmgl"
+)
+
+// TextureWriteInfo describes a region of a texture that should be
+// replaced.
+type TextureWriteInfo struct {
+	Texture         render.Texture
+	MipmapLevel     uint32
+	CubeFace        uint32
+	Layer           uint32
+	X               uint32
+	Y               uint32
+	Width           uint32
+	Height          uint32
+	Layers          uint32
+	RowLength       uint32
+	Data            []byte
+	Buffer          render.Buffer
+	BufferOffset    uint32
+	GenerateMipmaps bool
+}
+
+func (q *Queue) WriteTexture(info TextureWriteInfo) {
+	texture := info.Texture.(*Texture)
+	defer trackError("Error writing texture", texture.label)()
+
+	if glContext.IsNull() {
+		logger.Error("Texture writes require the WebGL context to be set",
+			slog.String("label", texture.label),
+		)
+		return
+	}
+
+	if texture.compressedFormat != 0 {
+		q.writeCompressedTexture(texture, info)
+		return
+	}
+
+	if texture.format == render.DataFormatUnsupported {
+		logger.Error("Texture writes are only supported for color textures with a known format",
+			slog.String("label", texture.label),
+		)
+		return
+	}
+
+	target := texture.kind
+	if texture.kind == wasmgl.TEXTURE_CUBE_MAP {
+		target = wasmgl.TEXTURE_CUBE_MAP_POSITIVE_X + wasmgl.GLenum(info.CubeFace)
+	}
+	dataFormat := glDataFormat(texture.format)
+	componentType := glDataComponentType(texture.format)
+
+	wasmgl.BindTexture(texture.kind, texture.raw)
+	if info.Buffer == nil && info.RowLength == 0 {
+		switch texture.kind {
+		case wasmgl.TEXTURE_2D_ARRAY, wasmgl.TEXTURE_3D:
+			texSubImage3D(target,
+				wasmgl.GLint(info.MipmapLevel),
+				wasmgl.GLint(info.X),
+				wasmgl.GLint(info.Y),
+				wasmgl.GLint(info.Layer),
+				wasmgl.GLsizei(info.Width),
+				wasmgl.GLsizei(info.Height),
+				wasmgl.GLsizei(max(info.Layers, 1)),
+				dataFormat,
+				componentType,
+				info.Data,
+			)
+		default:
+			texSubImage2D(target,
+				wasmgl.GLint(info.MipmapLevel),
+				wasmgl.GLint(info.X),
+				wasmgl.GLint(info.Y),
+				wasmgl.GLsizei(info.Width),
+				wasmgl.GLsizei(info.Height),
+				dataFormat,
+				componentType,
+				info.Data,
+			)
+		}
+	} else {
+		q.writeTextureUnpacked(texture, target, info, dataFormat, componentType)
+	}
+	if canGenerateMipmaps(texture.label, texture.format, info.GenerateMipmaps) {
+		wasmgl.GenerateMipmap(texture.kind)
+	}
+}
+
+// writeTextureUnpacked replaces a region of a texture from a pixel unpack
+// buffer or from client data with a custom row length, neither of which
+// wasmgl supports.
+func (q *Queue) writeTextureUnpacked(texture *Texture, target wasmgl.GLenum, info TextureWriteInfo, dataFormat, componentType wasmgl.GLenum) {
+	var pixels any
+	if info.Buffer != nil {
+		buffer := info.Buffer.(*Buffer)
+		wasmgl.BindBuffer(wasmgl.PIXEL_UNPACK_BUFFER, buffer.raw)
+		defer wasmgl.BindBuffer(wasmgl.PIXEL_UNPACK_BUFFER, wasmgl.NilBuffer)
+		pixels = info.BufferOffset
+	} else {
+		pixels = jsPixelData(info.Data, componentType)
+	}
+
+	if info.RowLength > 0 {
+		glContext.Call("pixelStorei", wasmgl.UNPACK_ROW_LENGTH, info.RowLength)
+		defer glContext.Call("pixelStorei", wasmgl.UNPACK_ROW_LENGTH, 0)
+	}
+
+	switch texture.kind {
+	case wasmgl.TEXTURE_2D_ARRAY, wasmgl.TEXTURE_3D:
+		glContext.Call("texSubImage3D",
+			target,
+			info.MipmapLevel,
+			info.X,
+			info.Y,
+			info.Layer,
+			info.Width,
+			info.Height,
+			max(info.Layers, 1),
+			dataFormat,
+			componentType,
+			pixels,
+		)
+	default:
+		glContext.Call("texSubImage2D",
+			target,
+			info.MipmapLevel,
+			info.X,
+			info.Y,
+			info.Width,
+			info.Height,
+			dataFormat,
+			componentType,
+			pixels,
+		)
+	}
+}
+
+// writeCompressedTexture replaces a region of a compressed texture. The
+// region needs to be aligned to the block size of the format.
+func (q *Queue) writeCompressedTexture(texture *Texture, info TextureWriteInfo) {
+	if info.Buffer != nil || info.RowLength > 0 || info.GenerateMipmaps {
+		logger.Error("Compressed texture writes only support tightly packed client data",
+			slog.String("label", texture.label),
+		)
+		return
+	}
+	data := jsPixelData(info.Data, wasmgl.UNSIGNED_BYTE)
+	wasmgl.BindTexture(texture.kind, texture.raw)
+	switch texture.kind {
+	case wasmgl.TEXTURE_CUBE_MAP:
+		glContext.Call("compressedTexSubImage2D",
+			wasmgl.TEXTURE_CUBE_MAP_POSITIVE_X+wasmgl.GLenum(info.CubeFace),
+			info.MipmapLevel,
+			info.X,
+			info.Y,
+			info.Width,
+			info.Height,
+			texture.compressedFormat,
+			data,
+		)
+	default:
+		glContext.Call("compressedTexSubImage2D",
+			texture.kind,
+			info.MipmapLevel,
+			info.X,
+			info.Y,
+			info.Width,
+			info.Height,
+			texture.compressedFormat,
+			data,
+		)
+	}
+}
@@ -10,6 +10,19 @@ import (
 func NewVertexArray(info render.VertexArrayInfo) *VertexArray {
 	defer trackError("Error creating vertex array", info.Label)()
 
+	result := &VertexArray{
+		label:       info.Label,
+		raw:         createVertexArray(info),
+		indexFormat: glIndexFormat(info.IndexFormat),
+	}
+	result.recreate = retained(func() {
+		result.raw = createVertexArray(info)
+	})
+	result.id = vertexArrays.Allocate(result)
+	return result
+}
+
+func createVertexArray(info render.VertexArrayInfo) wasmgl.VertexArray {
 	raw := wasmgl.CreateVertexArray()
 	wasmgl.BindVertexArray(raw)
 	for _, attribute := range info.Attributes {
@@ -29,14 +42,7 @@ func NewVertexArray(info render.VertexArrayInfo) *VertexArray {
 		wasmgl.BindBuffer(indexBuffer.kind, indexBuffer.raw)
 	}
 	wasmgl.BindVertexArray(wasmgl.NilVertexArray)
-
-	result := &VertexArray{
-		label:       info.Label,
-		raw:         raw,
-		indexFormat: glIndexFormat(info.IndexFormat),
-	}
-	result.id = vertexArrays.Allocate(result)
-	return result
+	return raw
 }
 
 type VertexArray struct {
@@ -46,6 +52,8 @@ type VertexArray struct {
 	id          uint32
 	raw         wasmgl.VertexArray
 	indexFormat wasmgl.GLenum
+
+	recreate func()
 }
 
 func (a *VertexArray) Label() string {
@@ -0,0 +1,184 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mokiat/lacking-js/render/ktx2"
+	"github.com/mokiat/lacking/render"
+)
+
+// ErrTranscodingUnsupported is returned when a KTX2 texture holds Basis
+// Universal data, which would need to be transcoded to a GPU format.
+// Transcoding is not performed at runtime; such assets need to be
+// transcoded offline instead.
+var ErrTranscodingUnsupported = errors.New("basis universal transcoding is not supported")
+
+// ktx2CompressedFormats maps KTX2 formats to compressed formats.
+var ktx2CompressedFormats = map[ktx2.VkFormat]CompressedFormat{
+	ktx2.VkFormatBC1RGBAUNorm:      CompressedFormatBC1,
+	ktx2.VkFormatBC1RGBASRGB:       CompressedFormatBC1,
+	ktx2.VkFormatBC3UNorm:          CompressedFormatBC3,
+	ktx2.VkFormatBC3SRGB:           CompressedFormatBC3,
+	ktx2.VkFormatBC4UNorm:          CompressedFormatBC4,
+	ktx2.VkFormatBC5UNorm:          CompressedFormatBC5,
+	ktx2.VkFormatBC7UNorm:          CompressedFormatBC7,
+	ktx2.VkFormatBC7SRGB:           CompressedFormatBC7,
+	ktx2.VkFormatETC2R8G8B8UNorm:   CompressedFormatETC2RGB,
+	ktx2.VkFormatETC2R8G8B8SRGB:    CompressedFormatETC2RGB,
+	ktx2.VkFormatETC2R8G8B8A8UNorm: CompressedFormatETC2RGBA,
+	ktx2.VkFormatETC2R8G8B8A8SRGB:  CompressedFormatETC2RGBA,
+	ktx2.VkFormatEACR11UNorm:       CompressedFormatEACR11,
+	ktx2.VkFormatEACR11G11UNorm:    CompressedFormatEACRG11,
+	ktx2.VkFormatASTC4x4UNorm:      CompressedFormatASTC4x4,
+	ktx2.VkFormatASTC4x4SRGB:       CompressedFormatASTC4x4,
+	ktx2.VkFormatASTC6x6UNorm:      CompressedFormatASTC6x6,
+	ktx2.VkFormatASTC6x6SRGB:       CompressedFormatASTC6x6,
+	ktx2.VkFormatASTC8x8UNorm:      CompressedFormatASTC8x8,
+	ktx2.VkFormatASTC8x8SRGB:       CompressedFormatASTC8x8,
+}
+
+// ktx2DataFormats maps KTX2 formats to uncompressed data formats.
+var ktx2DataFormats = map[ktx2.VkFormat]render.DataFormat{
+	ktx2.VkFormatR8G8B8A8UNorm:      render.DataFormatRGBA8,
+	ktx2.VkFormatR8G8B8A8SRGB:       render.DataFormatRGBA8,
+	ktx2.VkFormatR16G16B16A16SFloat: render.DataFormatRGBA16F,
+	ktx2.VkFormatR32G32B32A32SFloat: render.DataFormatRGBA32F,
+	ktx2.VkFormatR8UNorm:            DataFormatR8,
+	ktx2.VkFormatR8G8UNorm:          DataFormatRG8,
+	ktx2.VkFormatR16SFloat:          DataFormatR16F,
+	ktx2.VkFormatR16G16SFloat:       DataFormatRG16F,
+	ktx2.VkFormatR32SFloat:          DataFormatR32F,
+	ktx2.VkFormatR32UInt:            DataFormatR32UI,
+	ktx2.VkFormatR8G8B8A8UInt:       DataFormatRGBA8UI,
+	ktx2.VkFormatB10G11R11UFloat:    DataFormatR11G11B10F,
+	ktx2.VkFormatA2B10G10R10UNorm:   DataFormatRGB10A2,
+}
+
+// CreateKTX2Texture creates a 2D or cube texture from a KTX2 file.
+// Compressed data is uploaded as is, so the device needs to support its
+// format (see Limits.SupportsCompressedFormat). There is no RGBA8 fallback
+// for unsupported compressed formats, so an uncompressed variant of the
+// asset should be loaded instead in that case. Basis Universal data is
+// reported through ErrTranscodingUnsupported.
+func (a *API) CreateKTX2Texture(label string, texture *ktx2.Texture) (render.Texture, error) {
+	if texture.IsBasisUniversal() {
+		return nil, ErrTranscodingUnsupported
+	}
+	if !texture.Decoded {
+		return nil, ktx2.ErrUnsupportedSupercompression
+	}
+	if texture.Layers > 1 || texture.Depth > 1 {
+		return nil, fmt.Errorf("array and 3D KTX2 textures are not supported")
+	}
+	isCube := texture.Faces == 6
+	if !isCube && texture.Faces > 1 {
+		return nil, fmt.Errorf("unexpected face count %d", texture.Faces)
+	}
+
+	if format, ok := ktx2CompressedFormats[texture.Format]; ok {
+		if isCube {
+			info := CompressedTextureCubeInfo{
+				Label:           label,
+				Format:          format,
+				GammaCorrection: texture.SRGB,
+			}
+			for level := range texture.Levels {
+				sides, err := ktx2CubeSides(texture, level)
+				if err != nil {
+					return nil, err
+				}
+				dimension, _ := texture.LevelSize(level)
+				info.MipmapLayers = append(info.MipmapLayers, CompressedCubeMipmapLayer{
+					Dimension:      dimension,
+					RightSideData:  sides[0],
+					LeftSideData:   sides[1],
+					BottomSideData: sides[2],
+					TopSideData:    sides[3],
+					FrontSideData:  sides[4],
+					BackSideData:   sides[5],
+				})
+			}
+			return a.CreateCompressedTextureCube(info)
+		}
+		info := CompressedTexture2DInfo{
+			Label:           label,
+			Format:          format,
+			GammaCorrection: texture.SRGB,
+		}
+		for level := range texture.Levels {
+			data, err := texture.Image(level, 0, 0)
+			if err != nil {
+				return nil, err
+			}
+			width, height := texture.LevelSize(level)
+			info.MipmapLayers = append(info.MipmapLayers, CompressedMipmapLayer{
+				Width:  width,
+				Height: height,
+				Data:   data,
+			})
+		}
+		return a.CreateCompressedTexture2D(info)
+	}
+
+	format, ok := ktx2DataFormats[texture.Format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported KTX2 format %d", texture.Format)
+	}
+	if isCube {
+		info := render.ColorTextureCubeInfo{
+			Label:           label,
+			GammaCorrection: texture.SRGB,
+			Format:          format,
+		}
+		for level := range texture.Levels {
+			sides, err := ktx2CubeSides(texture, level)
+			if err != nil {
+				return nil, err
+			}
+			dimension, _ := texture.LevelSize(level)
+			info.MipmapLayers = append(info.MipmapLayers, render.MipmapCubeLayer{
+				Dimension:      dimension,
+				RightSideData:  sides[0],
+				LeftSideData:   sides[1],
+				BottomSideData: sides[2],
+				TopSideData:    sides[3],
+				FrontSideData:  sides[4],
+				BackSideData:   sides[5],
+			})
+		}
+		return a.CreateColorTextureCube(info), nil
+	}
+	info := render.ColorTexture2DInfo{
+		Label:           label,
+		GammaCorrection: texture.SRGB,
+		Format:          format,
+	}
+	for level := range texture.Levels {
+		data, err := texture.Image(level, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		width, height := texture.LevelSize(level)
+		info.MipmapLayers = append(info.MipmapLayers, render.Mipmap2DLayer{
+			Width:  width,
+			Height: height,
+			Data:   data,
+		})
+	}
+	return a.CreateColorTexture2D(info), nil
+}
+
+// ktx2CubeSides returns the six faces of a cube texture level in KTX2
+// order (+X, -X, +Y, -Y, +Z, -Z).
+func ktx2CubeSides(texture *ktx2.Texture, level int) ([6][]byte, error) {
+	var sides [6][]byte
+	for face := range sides {
+		data, err := texture.Image(level, 0, face)
+		if err != nil {
+			return sides, err
+		}
+		sides[face] = data
+	}
+	return sides, nil
+}
@@ -0,0 +1,367 @@
+// Package ktx2 reads textures that are stored in the KTX 2.0 container
+// format.
+//
+// Images that are supercompressed with Zstandard or BasisLZ, as well as
+// Basis Universal UASTC images, are recognized but their data is not
+// decoded. Use IsBasisUniversal to detect the latter. This package does
+// not include a Basis Universal transcoder, so such files need to be
+// transcoded offline to a GPU format (or to RGBA8) before use.
+package ktx2
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrUnsupportedSupercompression is returned when the level data uses a
+// supercompression scheme that cannot be decoded.
+var ErrUnsupportedSupercompression = errors.New("unsupported supercompression scheme")
+
+var identifier = [12]byte{0xAB, 'K', 'T', 'X', ' ', '2', '0', 0xBB, '\r', '\n', 0x1A, '\n'}
+
+// VkFormat is the Vulkan format of the texture data.
+type VkFormat uint32
+
+const (
+	VkFormatUndefined          VkFormat = 0
+	VkFormatR8UNorm            VkFormat = 9
+	VkFormatR8G8UNorm          VkFormat = 16
+	VkFormatR8G8B8A8UInt       VkFormat = 41
+	VkFormatA2B10G10R10UNorm   VkFormat = 64
+	VkFormatR16SFloat          VkFormat = 76
+	VkFormatR16G16SFloat       VkFormat = 83
+	VkFormatR32UInt            VkFormat = 98
+	VkFormatR32SFloat          VkFormat = 100
+	VkFormatB10G11R11UFloat    VkFormat = 122
+	VkFormatR8G8B8A8UNorm      VkFormat = 37
+	VkFormatR8G8B8A8SRGB       VkFormat = 43
+	VkFormatR16G16B16A16SFloat VkFormat = 97
+	VkFormatR32G32B32A32SFloat VkFormat = 109
+	VkFormatBC1RGBAUNorm       VkFormat = 133
+	VkFormatBC1RGBASRGB        VkFormat = 134
+	VkFormatBC3UNorm           VkFormat = 137
+	VkFormatBC3SRGB            VkFormat = 138
+	VkFormatBC4UNorm           VkFormat = 139
+	VkFormatBC5UNorm           VkFormat = 141
+	VkFormatBC7UNorm           VkFormat = 145
+	VkFormatBC7SRGB            VkFormat = 146
+	VkFormatETC2R8G8B8UNorm    VkFormat = 147
+	VkFormatETC2R8G8B8SRGB     VkFormat = 148
+	VkFormatETC2R8G8B8A8UNorm  VkFormat = 151
+	VkFormatETC2R8G8B8A8SRGB   VkFormat = 152
+	VkFormatEACR11UNorm        VkFormat = 153
+	VkFormatEACR11G11UNorm     VkFormat = 155
+	VkFormatASTC4x4UNorm       VkFormat = 157
+	VkFormatASTC4x4SRGB        VkFormat = 158
+	VkFormatASTC6x6UNorm       VkFormat = 165
+	VkFormatASTC6x6SRGB        VkFormat = 166
+	VkFormatASTC8x8UNorm       VkFormat = 171
+	VkFormatASTC8x8SRGB        VkFormat = 172
+)
+
+// Supercompression is the scheme with which the level data is compressed
+// on top of the texture format.
+type Supercompression uint32
+
+const (
+	SupercompressionNone      Supercompression = 0
+	SupercompressionBasisLZ   Supercompression = 1
+	SupercompressionZstandard Supercompression = 2
+	SupercompressionZLIB      Supercompression = 3
+)
+
+// ColorModel is the color model of the data format descriptor.
+type ColorModel uint8
+
+const (
+	ColorModelUnspecified ColorModel = 0
+	ColorModelRGBSDA      ColorModel = 1
+	ColorModelETC1S       ColorModel = 163
+	ColorModelUASTC       ColorModel = 166
+)
+
+// transferFunctionSRGB is the data format descriptor transfer function
+// of sRGB encoded data.
+const transferFunctionSRGB = 2
+
+// Texture is a decoded KTX 2.0 file.
+type Texture struct {
+	Format           VkFormat
+	TypeSize         uint32
+	Width            uint32
+	Height           uint32
+	Depth            uint32
+	Layers           uint32
+	Faces            uint32
+	Supercompression Supercompression
+	ColorModel       ColorModel
+
+	// SRGB specifies whether the color data is sRGB encoded.
+	SRGB bool
+
+	// Levels holds the data of each mipmap level, starting with the
+	// largest one. The data is not supercompressed, unless the scheme is
+	// one that cannot be decoded (see Decoded).
+	Levels [][]byte
+
+	// Decoded specifies whether Levels holds the data in Format, as
+	// opposed to supercompressed data.
+	Decoded bool
+
+	// KeyValues holds the key/value metadata of the file.
+	KeyValues map[string][]byte
+
+	// GlobalData holds the supercompression global data (e.g. the BasisLZ
+	// codebooks).
+	GlobalData []byte
+}
+
+// IsBasisUniversal returns whether the texture holds Basis Universal data
+// (ETC1S or UASTC), which needs to be transcoded before it can be used.
+func (t *Texture) IsBasisUniversal() bool {
+	return t.Supercompression == SupercompressionBasisLZ ||
+		t.ColorModel == ColorModelETC1S ||
+		t.ColorModel == ColorModelUASTC
+}
+
+// LevelSize returns the dimensions of the specified mipmap level.
+func (t *Texture) LevelSize(level int) (width, height uint32) {
+	return max(t.Width>>level, 1), max(t.Height>>level, 1)
+}
+
+// Image returns the data of a single image of the specified mipmap
+// level. Images within a level are ordered by layer, then by face, then by
+// depth slice.
+func (t *Texture) Image(level, layer, face int) ([]byte, error) {
+	if level < 0 || level >= len(t.Levels) {
+		return nil, fmt.Errorf("level %d out of range", level)
+	}
+	if !t.Decoded {
+		return nil, ErrUnsupportedSupercompression
+	}
+	layers := max(int(t.Layers), 1)
+	if layer < 0 || layer >= layers {
+		return nil, fmt.Errorf("layer %d out of range", layer)
+	}
+	faces := max(int(t.Faces), 1)
+	if face < 0 || face >= faces {
+		return nil, fmt.Errorf("face %d out of range", face)
+	}
+	slices := 1
+	if t.Depth > 0 {
+		slices = max(int(t.Depth>>level), 1)
+	}
+	data := t.Levels[level]
+	count := layers * faces * slices
+	if len(data)%count != 0 {
+		return nil, fmt.Errorf("level %d size %d is not a multiple of %d images", level, len(data), count)
+	}
+	size := len(data) / count
+	index := (layer*faces + face) * slices
+	return data[index*size : (index+slices)*size], nil
+}
+
+// Read decodes a KTX 2.0 file.
+func Read(in io.Reader) (*Texture, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, fmt.Errorf("error reading data: %w", err)
+	}
+	return Decode(data)
+}
+
+// Decode decodes a KTX 2.0 file from memory.
+func Decode(data []byte) (*Texture, error) {
+	const headerSize = 12 + 9*4 + 4*4 + 2*8
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("file too small")
+	}
+	if !bytes.Equal(data[:12], identifier[:]) {
+		return nil, fmt.Errorf("invalid identifier")
+	}
+
+	r := reader{data: data, offset: 12}
+	texture := &Texture{
+		Format:    VkFormat(r.uint32()),
+		TypeSize:  r.uint32(),
+		Width:     r.uint32(),
+		Height:    r.uint32(),
+		Depth:     r.uint32(),
+		Layers:    r.uint32(),
+		Faces:     r.uint32(),
+		KeyValues: make(map[string][]byte),
+		Decoded:   true,
+	}
+	levelCount := max(int(r.uint32()), 1)
+	texture.Supercompression = Supercompression(r.uint32())
+
+	dfdOffset, dfdLength := r.uint32(), r.uint32()
+	kvdOffset, kvdLength := r.uint32(), r.uint32()
+	sgdOffset, sgdLength := r.uint64(), r.uint64()
+
+	// NOTE: Each level index entry is three 64 bit values. The count is
+	// validated before allocating, since it comes from the file.
+	const levelIndexSize = 3 * 8
+	if uint64(levelCount)*levelIndexSize > uint64(len(data)-r.offset) {
+		return nil, fmt.Errorf("level count %d exceeds file size %d", levelCount, len(data))
+	}
+
+	type levelIndex struct {
+		offset             uint64
+		length             uint64
+		uncompressedLength uint64
+	}
+	indices := make([]levelIndex, levelCount)
+	for i := range indices {
+		indices[i] = levelIndex{
+			offset:             r.uint64(),
+			length:             r.uint64(),
+			uncompressedLength: r.uint64(),
+		}
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("error reading header: %w", r.err)
+	}
+
+	if dfdLength > 0 {
+		dfd, err := section(data, uint64(dfdOffset), uint64(dfdLength))
+		if err != nil {
+			return nil, fmt.Errorf("error reading data format descriptor: %w", err)
+		}
+		texture.ColorModel, texture.SRGB = parseDFD(dfd)
+	}
+	if kvdLength > 0 {
+		kvd, err := section(data, uint64(kvdOffset), uint64(kvdLength))
+		if err != nil {
+			return nil, fmt.Errorf("error reading key/value data: %w", err)
+		}
+		if err := parseKVD(kvd, texture.KeyValues); err != nil {
+			return nil, fmt.Errorf("error reading key/value data: %w", err)
+		}
+	}
+	if sgdLength > 0 {
+		sgd, err := section(data, sgdOffset, sgdLength)
+		if err != nil {
+			return nil, fmt.Errorf("error reading supercompression global data: %w", err)
+		}
+		texture.GlobalData = sgd
+	}
+
+	texture.Levels = make([][]byte, levelCount)
+	for i, index := range indices {
+		level, err := section(data, index.offset, index.length)
+		if err != nil {
+			return nil, fmt.Errorf("error reading level %d: %w", i, err)
+		}
+		switch texture.Supercompression {
+		case SupercompressionNone:
+			texture.Levels[i] = level
+		case SupercompressionZLIB:
+			level, err = inflate(level, index.uncompressedLength)
+			if err != nil {
+				return nil, fmt.Errorf("error decompressing level %d: %w", i, err)
+			}
+			texture.Levels[i] = level
+		default:
+			// NOTE: The data is kept, so that it can be handed to an external
+			// decoder or transcoder.
+			texture.Levels[i] = level
+			texture.Decoded = false
+		}
+	}
+	return texture, nil
+}
+
+// parseDFD returns the color model and whether the data is sRGB encoded,
+// based on the first basic descriptor block.
+func parseDFD(dfd []byte) (ColorModel, bool) {
+	// The total size is followed by the block header (two words) and the
+	// word that holds the color model and transfer function.
+	if len(dfd) < 16 {
+		return ColorModelUnspecified, false
+	}
+	colorModel := ColorModel(dfd[12])
+	transferFunction := dfd[14]
+	return colorModel, transferFunction == transferFunctionSRGB
+}
+
+func parseKVD(kvd []byte, target map[string][]byte) error {
+	for offset := 0; offset+4 <= len(kvd); {
+		length := int(binary.LittleEndian.Uint32(kvd[offset:]))
+		offset += 4
+		if offset+length > len(kvd) {
+			return fmt.Errorf("entry exceeds bounds")
+		}
+		entry := kvd[offset : offset+length]
+		separator := bytes.IndexByte(entry, 0)
+		if separator < 0 {
+			return fmt.Errorf("entry key is not terminated")
+		}
+		target[string(entry[:separator])] = entry[separator+1:]
+		offset += (length + 3) &^ 3
+	}
+	return nil
+}
+
+// maxDeflateRatio is the largest ratio by which Deflate can compress
+// data. Sizes beyond it cannot be produced by valid streams.
+const maxDeflateRatio = 1032
+
+func inflate(data []byte, size uint64) ([]byte, error) {
+	if size > uint64(len(data))*maxDeflateRatio {
+		return nil, fmt.Errorf("uncompressed size %d is not possible for %d compressed bytes", size, len(data))
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	// NOTE: The data is read incrementally instead of allocating the
+	// declared size upfront, so that a wrong size cannot cause a large
+	// allocation on its own.
+	result, err := io.ReadAll(io.LimitReader(zr, int64(size)+1))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(result)) != size {
+		return nil, fmt.Errorf("uncompressed size %d does not match declared size %d", len(result), size)
+	}
+	return result, nil
+}
+
+func section(data []byte, offset, length uint64) ([]byte, error) {
+	if offset > uint64(len(data)) || length > uint64(len(data))-offset {
+		return nil, fmt.Errorf("section [%d:+%d] exceeds file size %d", offset, length, len(data))
+	}
+	return data[offset : offset+length], nil
+}
+
+type reader struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+func (r *reader) uint32() uint32 {
+	if r.err != nil || r.offset+4 > len(r.data) {
+		r.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	value := binary.LittleEndian.Uint32(r.data[r.offset:])
+	r.offset += 4
+	return value
+}
+
+func (r *reader) uint64() uint64 {
+	if r.err != nil || r.offset+8 > len(r.data) {
+		r.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	value := binary.LittleEndian.Uint64(r.data[r.offset:])
+	r.offset += 8
+	return value
+}
@@ -0,0 +1,335 @@
+package ktx2_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/mokiat/lacking-js/render/ktx2"
+)
+
+var identifier = []byte{0xAB, 'K', 'T', 'X', ' ', '2', '0', 0xBB, '\r', '\n', 0x1A, '\n'}
+
+// sampleFile describes a KTX2 file that is assembled by encode.
+type sampleFile struct {
+	format           ktx2.VkFormat
+	width            uint32
+	height           uint32
+	layers           uint32
+	faces            uint32
+	supercompression ktx2.Supercompression
+	dfd              []byte
+	kvd              []byte
+	levels           [][]byte
+
+	// uncompressedLengths overrides the uncompressed length of each level,
+	// which otherwise matches the level length.
+	uncompressedLengths []uint64
+
+	// levelCount overrides the level count in the header, which otherwise
+	// matches the number of levels.
+	levelCount uint32
+}
+
+func (f sampleFile) encode() []byte {
+	const headerSize = 12 + 9*4 + 4*4 + 2*8
+	levelCount := uint32(len(f.levels))
+	if f.levelCount != 0 {
+		levelCount = f.levelCount
+	}
+	dfdOffset := uint32(headerSize + len(f.levels)*24)
+	kvdOffset := dfdOffset + uint32(len(f.dfd))
+	dataOffset := uint64(kvdOffset) + uint64(len(f.kvd))
+
+	var buf bytes.Buffer
+	buf.Write(identifier)
+	write := func(value any) {
+		binary.Write(&buf, binary.LittleEndian, value)
+	}
+	write([]uint32{uint32(f.format), 1, f.width, f.height, 0, f.layers, f.faces, levelCount, uint32(f.supercompression)})
+	write([]uint32{dfdOffset, uint32(len(f.dfd)), kvdOffset, uint32(len(f.kvd))})
+	write([]uint64{0, 0})
+	offset := dataOffset
+	for i, level := range f.levels {
+		uncompressedLength := uint64(len(level))
+		if i < len(f.uncompressedLengths) {
+			uncompressedLength = f.uncompressedLengths[i]
+		}
+		write([]uint64{offset, uint64(len(level)), uncompressedLength})
+		offset += uint64(len(level))
+	}
+	buf.Write(f.dfd)
+	buf.Write(f.kvd)
+	for _, level := range f.levels {
+		buf.Write(level)
+	}
+	return buf.Bytes()
+}
+
+func dfd(colorModel ktx2.ColorModel, transferFunction byte) []byte {
+	result := make([]byte, 16)
+	binary.LittleEndian.PutUint32(result, uint32(len(result)))
+	result[12] = byte(colorModel)
+	result[14] = transferFunction
+	return result
+}
+
+func kvdEntry(key, value string) []byte {
+	entry := append([]byte(key), 0)
+	entry = append(entry, value...)
+	result := binary.LittleEndian.AppendUint32(nil, uint32(len(entry)))
+	result = append(result, entry...)
+	for len(result)%4 != 0 {
+		result = append(result, 0)
+	}
+	return result
+}
+
+func deflate(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatalf("error compressing: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("error compressing: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	level0 := bytes.Repeat([]byte{1, 2, 3, 4}, 4)
+	level1 := []byte{5, 6, 7, 8}
+	data := sampleFile{
+		format: ktx2.VkFormatR8G8B8A8SRGB,
+		width:  2,
+		height: 2,
+		dfd:    dfd(ktx2.ColorModelRGBSDA, 2),
+		kvd:    kvdEntry("KTXorientation", "rd\x00"),
+		levels: [][]byte{level0, level1},
+	}.encode()
+
+	texture, err := ktx2.Decode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if texture.Format != ktx2.VkFormatR8G8B8A8SRGB {
+		t.Errorf("expected format %d, got %d", ktx2.VkFormatR8G8B8A8SRGB, texture.Format)
+	}
+	if texture.Width != 2 || texture.Height != 2 {
+		t.Errorf("expected size 2x2, got %dx%d", texture.Width, texture.Height)
+	}
+	if !texture.SRGB {
+		t.Errorf("expected sRGB data")
+	}
+	if !texture.Decoded {
+		t.Errorf("expected decoded data")
+	}
+	if texture.IsBasisUniversal() {
+		t.Errorf("expected no Basis Universal data")
+	}
+	if value := string(texture.KeyValues["KTXorientation"]); value != "rd\x00" {
+		t.Errorf("expected orientation %q, got %q", "rd\x00", value)
+	}
+	if len(texture.Levels) != 2 {
+		t.Fatalf("expected 2 levels, got %d", len(texture.Levels))
+	}
+	if width, height := texture.LevelSize(1); width != 1 || height != 1 {
+		t.Errorf("expected level 1 size 1x1, got %dx%d", width, height)
+	}
+	image, err := texture.Image(1, 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(image, level1) {
+		t.Errorf("expected level 1 data %v, got %v", level1, image)
+	}
+	if _, err := texture.Image(2, 0, 0); err == nil {
+		t.Errorf("expected error for level out of range")
+	}
+	if _, err := texture.Image(0, 1, 0); err == nil {
+		t.Errorf("expected error for layer out of range")
+	}
+	if _, err := texture.Image(0, -1, 0); err == nil {
+		t.Errorf("expected error for negative layer")
+	}
+	if _, err := texture.Image(0, 0, 1); err == nil {
+		t.Errorf("expected error for face out of range")
+	}
+}
+
+func TestDecodeCube(t *testing.T) {
+	var level []byte
+	for face := range 6 {
+		level = append(level, bytes.Repeat([]byte{byte(face)}, 4)...)
+	}
+	data := sampleFile{
+		format: ktx2.VkFormatR8G8B8A8UNorm,
+		width:  1,
+		height: 1,
+		faces:  6,
+		levels: [][]byte{level},
+	}.encode()
+
+	texture, err := ktx2.Decode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for face := range 6 {
+		image, err := texture.Image(0, 0, face)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := bytes.Repeat([]byte{byte(face)}, 4)
+		if !bytes.Equal(image, expected) {
+			t.Errorf("expected face %d data %v, got %v", face, expected, image)
+		}
+	}
+}
+
+func TestDecodeZLIB(t *testing.T) {
+	level := bytes.Repeat([]byte{9, 8, 7, 6}, 64)
+	data := sampleFile{
+		format:              ktx2.VkFormatR8G8B8A8UNorm,
+		width:               8,
+		height:              8,
+		supercompression:    ktx2.SupercompressionZLIB,
+		levels:              [][]byte{deflate(t, level)},
+		uncompressedLengths: []uint64{uint64(len(level))},
+	}.encode()
+
+	texture, err := ktx2.Decode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !texture.Decoded {
+		t.Errorf("expected decoded data")
+	}
+	if !bytes.Equal(texture.Levels[0], level) {
+		t.Errorf("decompressed level does not match")
+	}
+}
+
+func TestDecodeBasisUniversal(t *testing.T) {
+	data := sampleFile{
+		format:           ktx2.VkFormatUndefined,
+		width:            4,
+		height:           4,
+		supercompression: ktx2.SupercompressionBasisLZ,
+		dfd:              dfd(ktx2.ColorModelETC1S, 2),
+		levels:           [][]byte{{1, 2, 3}},
+	}.encode()
+
+	texture, err := ktx2.Decode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !texture.IsBasisUniversal() {
+		t.Errorf("expected Basis Universal data")
+	}
+	if texture.Decoded {
+		t.Errorf("expected data to not be decoded")
+	}
+	if _, err := texture.Image(0, 0, 0); !errors.Is(err, ktx2.ErrUnsupportedSupercompression) {
+		t.Errorf("expected ErrUnsupportedSupercompression, got %v", err)
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	valid := sampleFile{
+		format: ktx2.VkFormatR8G8B8A8UNorm,
+		width:  1,
+		height: 1,
+		levels: [][]byte{{1, 2, 3, 4}},
+	}
+	compressed := deflate(t, []byte{1, 2, 3, 4})
+
+	testCases := []struct {
+		name string
+		data func() []byte
+	}{
+		{
+			name: "empty",
+			data: func() []byte {
+				return nil
+			},
+		},
+		{
+			name: "truncated header",
+			data: func() []byte {
+				return valid.encode()[:40]
+			},
+		},
+		{
+			name: "invalid identifier",
+			data: func() []byte {
+				data := valid.encode()
+				data[1] = 'X'
+				return data
+			},
+		},
+		{
+			name: "level count exceeds file",
+			data: func() []byte {
+				file := valid
+				file.levelCount = 0xFFFFFFFF
+				return file.encode()
+			},
+		},
+		{
+			name: "level exceeds file",
+			data: func() []byte {
+				data := valid.encode()
+				return data[:len(data)-1]
+			},
+		},
+		{
+			name: "key/value entry exceeds section",
+			data: func() []byte {
+				file := valid
+				file.kvd = binary.LittleEndian.AppendUint32(nil, 100)
+				return file.encode()
+			},
+		},
+		{
+			name: "impossible uncompressed length",
+			data: func() []byte {
+				file := valid
+				file.supercompression = ktx2.SupercompressionZLIB
+				file.levels = [][]byte{compressed}
+				file.uncompressedLengths = []uint64{1 << 40}
+				return file.encode()
+			},
+		},
+		{
+			name: "mismatched uncompressed length",
+			data: func() []byte {
+				file := valid
+				file.supercompression = ktx2.SupercompressionZLIB
+				file.levels = [][]byte{compressed}
+				file.uncompressedLengths = []uint64{8}
+				return file.encode()
+			},
+		},
+		{
+			name: "invalid compressed data",
+			data: func() []byte {
+				file := valid
+				file.supercompression = ktx2.SupercompressionZLIB
+				file.uncompressedLengths = []uint64{4}
+				return file.encode()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ktx2.Decode(tc.data()); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package render
+
+import (
+	"github.com/mokiat/lacking-js/render/internal"
+	"github.com/mokiat/lacking/render"
+)
+
+// TextureCubeSide specifies a side of a cube texture.
+type TextureCubeSide uint8
+
+const (
+	// TextureCubeSideRight is the side that faces the positive X axis.
+	TextureCubeSideRight TextureCubeSide = iota
+
+	// TextureCubeSideLeft is the side that faces the negative X axis.
+	TextureCubeSideLeft
+
+	// TextureCubeSideBottom is the side that faces the positive Y axis.
+	TextureCubeSideBottom
+
+	// TextureCubeSideTop is the side that faces the negative Y axis.
+	TextureCubeSideTop
+
+	// TextureCubeSideFront is the side that faces the positive Z axis.
+	TextureCubeSideFront
+
+	// TextureCubeSideBack is the side that faces the negative Z axis.
+	TextureCubeSideBack
+)
+
+// TextureWriteInfo describes a region of a texture that should be
+// replaced through Queue.WriteTexture. The data is expected in the format
+// of the texture.
+type TextureWriteInfo struct {
+
+	// Texture is the texture that should be written to.
+	Texture render.Texture
+
+	// MipmapLevel is the mipmap level that should be written to.
+	MipmapLevel uint32
+
+	// CubeSide is the side that should be written to, in case of a cube
+	// texture.
+	CubeSide TextureCubeSide
+
+	// Layer is the first array layer (or depth slice of a 3D texture) that
+	// should be written to.
+	Layer uint32
+
+	// X is the horizontal offset of the region.
+	X uint32
+
+	// Y is the vertical offset of the region.
+	Y uint32
+
+	// Width is the width of the region.
+	Width uint32
+
+	// Height is the height of the region.
+	Height uint32
+
+	// Layers is the number of array layers (or depth slices of a 3D
+	// texture) that should be written to. Zero is treated as one.
+	Layers uint32
+
+	// RowLength is the number of pixels in a row of the source data, if
+	// the region is part of a larger image. Zero means that rows are
+	// tightly packed.
+	RowLength uint32
+
+	// Data holds the pixels of the region. It is ignored if Buffer is
+	// specified.
+	Data []byte
+
+	// Buffer is an optional pixel unpack buffer (see
+	// API.CreatePixelUnpackBuffer) from which the pixels are read.
+	Buffer render.Buffer
+
+	// BufferOffset is the offset in bytes within Buffer at which the
+	// pixels start.
+	BufferOffset uint32
+
+	// GenerateMipmaps specifies whether the mipmap levels of the texture
+	// should be regenerated after the write.
+	GenerateMipmaps bool
+}
+
+// Queue is the render.Queue of the API. It adds operations that are not
+// part of render.Queue.
+type Queue struct {
+	*internal.Queue
+}
+
+// WriteTexture replaces a region of a texture, either from client memory
+// or from a pixel unpack buffer.
+func (q *Queue) WriteTexture(info TextureWriteInfo) {
+	q.Queue.WriteTexture(internal.TextureWriteInfo{
+		Texture:         info.Texture,
+		MipmapLevel:     info.MipmapLevel,
+		CubeFace:        uint32(info.CubeSide),
+		Layer:           info.Layer,
+		X:               info.X,
+		Y:               info.Y,
+		Width:           info.Width,
+		Height:          info.Height,
+		Layers:          info.Layers,
+		RowLength:       info.RowLength,
+		Data:            info.Data,
+		Buffer:          info.Buffer,
+		BufferOffset:    info.BufferOffset,
+		GenerateMipmaps: info.GenerateMipmaps,
+	})
+}
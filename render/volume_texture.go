@@ -0,0 +1,120 @@
+package render
+
+import (
+	"github.com/mokiat/lacking-js/render/internal"
+	"github.com/mokiat/lacking/render"
+)
+
+// MipmapVolumeLayer holds the pixels of a single mipmap level of a 2D
+// array or 3D texture.
+type MipmapVolumeLayer struct {
+
+	// Width is the width of the mipmap level.
+	Width uint32
+
+	// Height is the height of the mipmap level.
+	Height uint32
+
+	// Depth is the number of array layers of a 2D array texture or the
+	// number of depth slices of a 3D texture.
+	Depth uint32
+
+	// Data holds the pixels of all layers (or slices), one after the other.
+	// It can be nil, in which case the contents are left undefined and can
+	// be populated later through Queue.WriteTexture or by rendering to a
+	// framebuffer.
+	Data []byte
+}
+
+// ColorTexture2DArrayInfo describes a color 2D array texture. Each layer of
+// the texture can be attached to a framebuffer on its own by specifying
+// the layer index as the Depth of the render.TextureAttachment.
+type ColorTexture2DArrayInfo struct {
+
+	// Label is an optional name for the texture, used for debugging.
+	Label string
+
+	// MipmapLayers holds the mipmap levels of the texture. At least one
+	// level needs to be specified and all levels need to have the same
+	// number of layers.
+	MipmapLayers []MipmapVolumeLayer
+
+	// GenerateMipmaps specifies whether the remaining mipmap levels should
+	// be generated, in case only the first level is specified.
+	GenerateMipmaps bool
+
+	// GammaCorrection specifies whether the data is in sRGB space.
+	GammaCorrection bool
+
+	// Format is the format of the texture data.
+	Format render.DataFormat
+}
+
+// ColorTexture3DInfo describes a color 3D (volume) texture. Each depth slice
+// of the texture can be attached to a framebuffer on its own by specifying
+// the slice index as the Depth of the render.TextureAttachment.
+type ColorTexture3DInfo struct {
+
+	// Label is an optional name for the texture, used for debugging.
+	Label string
+
+	// MipmapLayers holds the mipmap levels of the texture. At least one
+	// level needs to be specified. Unlike array textures, the depth of a
+	// 3D texture halves with each level.
+	MipmapLayers []MipmapVolumeLayer
+
+	// GenerateMipmaps specifies whether the remaining mipmap levels should
+	// be generated, in case only the first level is specified.
+	GenerateMipmaps bool
+
+	// GammaCorrection specifies whether the data is in sRGB space.
+	GammaCorrection bool
+
+	// Format is the format of the texture data.
+	Format render.DataFormat
+}
+
+// CreateColorTexture2DArray creates a new color 2D array texture. It
+// returns an error if no mipmap layers are specified.
+func (a *API) CreateColorTexture2DArray(info ColorTexture2DArrayInfo) (render.Texture, error) {
+	texture, err := internal.NewColorTexture2DArray(internal.ColorTexture2DArrayInfo{
+		Label:           info.Label,
+		MipmapLayers:    volumeMipmapLayers(info.MipmapLayers),
+		GenerateMipmaps: info.GenerateMipmaps,
+		GammaCorrection: info.GammaCorrection,
+		Format:          info.Format,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return texture, nil
+}
+
+// CreateColorTexture3D creates a new color 3D texture. It returns an
+// error if no mipmap layers are specified.
+func (a *API) CreateColorTexture3D(info ColorTexture3DInfo) (render.Texture, error) {
+	texture, err := internal.NewColorTexture3D(internal.ColorTexture3DInfo{
+		Label:           info.Label,
+		MipmapLayers:    volumeMipmapLayers(info.MipmapLayers),
+		GenerateMipmaps: info.GenerateMipmaps,
+		GammaCorrection: info.GammaCorrection,
+		Format:          info.Format,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return texture, nil
+}
+
+func volumeMipmapLayers(layers []MipmapVolumeLayer) []internal.MipmapVolumeLayer {
+	result := make([]internal.MipmapVolumeLayer, len(layers))
+	for i, layer := range layers {
+		result[i] = internal.MipmapVolumeLayer{
+			Width:  layer.Width,
+			Height: layer.Height,
+			Depth:  layer.Depth,
+			Data:   layer.Data,
+		}
+	}
+	return result
+}